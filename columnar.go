@@ -0,0 +1,193 @@
+package intset
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// Columnar encodes a collection of sets whose elements all lie in
+// [0, Universe).
+//
+// The sets are transposed into one column per value x present in any
+// of them, holding the indices of the sets that contain x, and each
+// column is stored as its runs of consecutive indices. Collections in
+// which many sets share the same values therefore encode compactly,
+// and values present in no set cost nothing, however large the
+// universe.
+//
+// A collection holds at most MaxColumnarSets sets.
+type Columnar[E Integer] struct {
+	Universe E
+}
+
+// MaxColumnarSets is the largest number of sets Columnar encodes.
+// It bounds the allocation made by Decode, since the count read from
+// the input is otherwise unchecked.
+const MaxColumnarSets = 1 << 20
+
+// Encode returns the columnar encoding of sets. It returns an error if
+// any set has an element outside [0, c.Universe), or if there are more
+// than MaxColumnarSets sets.
+func (c Columnar[E]) Encode(sets []*IntSet[E]) ([]byte, error) {
+	universe, err := c.universe()
+	if err != nil {
+		return nil, err
+	}
+	if len(sets) > MaxColumnarSets {
+		return nil, fmt.Errorf("intset: %d sets exceeds columnar limit %d", len(sets), MaxColumnarSets)
+	}
+
+	// Only the values present in some set get a column, so the cost
+	// follows the input rather than the universe.
+	var present IntSet[int]
+	columns := make(map[int]*IntSet[int])
+	for i, s := range sets {
+		if !s.IsEmpty() && s.Max() >= c.Universe {
+			return nil, fmt.Errorf("intset: set %d has element %d outside universe %d", i, s.Max(), c.Universe)
+		}
+
+		s.forEach(func(x E) {
+			col := columns[int(x)]
+			if col == nil {
+				col = &IntSet[int]{}
+				columns[int(x)] = col
+				present.Add(int(x))
+			}
+			col.Add(i)
+		})
+	}
+
+	b := binary.AppendUvarint(nil, uint64(universe))
+	b = binary.AppendUvarint(b, uint64(len(sets)))
+	b = binary.AppendUvarint(b, uint64(len(columns)))
+
+	prevX := 0
+	present.forEach(func(x int) {
+		col := columns[x]
+		b = binary.AppendUvarint(b, uint64(x-prevX))
+		b = binary.AppendUvarint(b, uint64(col.MinIntervalCount()))
+		prevX = x + 1
+
+		prev := 0
+		col.forEachRun(func(lo, hi int) {
+			b = binary.AppendUvarint(b, uint64(lo-prev))
+			b = binary.AppendUvarint(b, uint64(hi-lo))
+			prev = hi
+		})
+	})
+
+	return b, nil
+}
+
+// universe returns c.Universe as an int. It returns an error if the
+// universe is negative or does not fit in an int.
+func (c Columnar[E]) universe() (int, error) {
+	if c.Universe < 0 {
+		return 0, fmt.Errorf("intset: negative universe %d", c.Universe)
+	}
+	if uint64(c.Universe) > MaxInt {
+		return 0, fmt.Errorf("intset: universe %d out of range", c.Universe)
+	}
+
+	return int(c.Universe), nil
+}
+
+// errColumnar is returned by Columnar.Decode for malformed input.
+var errColumnar = errors.New("intset: malformed columnar encoding")
+
+// Decode returns the sets encoded in data by Encode.
+// It returns an error if data is malformed or was encoded
+// with a different universe.
+func (c Columnar[E]) Decode(data []byte) ([]*IntSet[E], error) {
+	next := func() (int, error) {
+		v, n := binary.Uvarint(data)
+		if n <= 0 || v > MaxInt {
+			return 0, errColumnar
+		}
+
+		data = data[n:]
+		return int(v), nil
+	}
+
+	want, err := c.universe()
+	if err != nil {
+		return nil, err
+	}
+
+	universe, err := next()
+	if err != nil {
+		return nil, err
+	}
+	if universe != want {
+		return nil, fmt.Errorf("intset: columnar universe %d, want %d", universe, want)
+	}
+
+	n, err := next()
+	if err != nil {
+		return nil, err
+	}
+	if n > MaxColumnarSets {
+		return nil, errColumnar
+	}
+
+	sets := make([]*IntSet[E], n)
+	for i := range sets {
+		sets[i] = &IntSet[E]{}
+	}
+
+	m, err := next()
+	if err != nil {
+		return nil, err
+	}
+	if m > universe {
+		return nil, errColumnar
+	}
+
+	prevX := 0
+	for ; m > 0; m-- {
+		gap, err := next()
+		if err != nil {
+			return nil, err
+		}
+
+		x := prevX + gap
+		if x < prevX || x >= universe {
+			return nil, errColumnar
+		}
+		prevX = x + 1
+
+		runs, err := next()
+		if err != nil {
+			return nil, err
+		}
+
+		prev := 0
+		for ; runs > 0; runs-- {
+			gap, err := next()
+			if err != nil {
+				return nil, err
+			}
+			length, err := next()
+			if err != nil {
+				return nil, err
+			}
+
+			lo := prev + gap
+			if lo < prev || length > n-lo {
+				return nil, errColumnar
+			}
+
+			for _, s := range sets[lo : lo+length] {
+				s.Add(E(x))
+			}
+			prev = lo + length
+		}
+	}
+
+	if len(data) != 0 {
+		return nil, errColumnar
+	}
+
+	return sets, nil
+}
@@ -0,0 +1,126 @@
+package intset_test
+
+import (
+	"encoding/binary"
+	"math/rand"
+	"testing"
+
+	"github.com/weiwenchen2022/intset"
+)
+
+func TestColumnarRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	const universe = 1 << 12
+
+	r := rand.New(rand.NewSource(1))
+	sets := []*intset.IntSet[int]{new(intset.IntSet[int])}
+	for i := 0; i < 20; i++ {
+		s := new(intset.IntSet[int])
+		s.AddAll(randValues(r)...)
+		sets = append(sets, s)
+	}
+	sets = append(sets, sets[1].Copy(), sets[1].Copy(), new(intset.IntSet[int]))
+
+	c := intset.Columnar[int]{Universe: universe}
+	data, err := c.Encode(sets)
+	if err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+
+	got, err := c.Decode(data)
+	if err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+
+	if len(got) != len(sets) {
+		t.Fatalf("Decode: got %d sets, want %d", len(got), len(sets))
+	}
+
+	for i := range sets {
+		if !got[i].Equals(sets[i]) {
+			t.Errorf("Decode #%d: got %s, want %s", i, got[i], sets[i])
+		}
+	}
+
+	if _, err := c.Decode(data[:len(data)-1]); err == nil {
+		t.Error("Decode of truncated data: got nil error")
+	}
+
+	if _, err := (intset.Columnar[int]{Universe: 10}).Decode(data); err == nil {
+		t.Error("Decode with wrong universe: got nil error")
+	}
+
+	for _, n := range []uint64{intset.MaxColumnarSets + 1, 1 << 30, 1 << 50} {
+		bad := binary.AppendUvarint(binary.AppendUvarint(nil, 10), n)
+		if _, err := (intset.Columnar[int]{Universe: 10}).Decode(bad); err == nil {
+			t.Errorf("Decode with %d sets: got nil error", n)
+		}
+	}
+}
+
+func TestColumnarSparseUniverse(t *testing.T) {
+	t.Parallel()
+
+	var s1, s2 intset.IntSet[int]
+	s1.AddAll(3, 1<<16)
+	s2.AddAll(1 << 16)
+	sets := []*intset.IntSet[int]{&s1, &s2}
+
+	// Values present in no set cost nothing, so a huge universe
+	// encodes as small as the values in use.
+	c := intset.Columnar[int]{Universe: intset.MaxInt}
+	data, err := c.Encode(sets)
+	if err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+	if len(data) > 32 {
+		t.Errorf("Encode: got %d bytes, want at most 32", len(data))
+	}
+
+	got, err := c.Decode(data)
+	if err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+	if len(got) != len(sets) {
+		t.Fatalf("Decode: got %d sets, want %d", len(got), len(sets))
+	}
+	for i := range sets {
+		if !got[i].Equals(sets[i]) {
+			t.Errorf("Decode #%d: got %s, want %s", i, got[i], sets[i])
+		}
+	}
+
+	// A column beyond the universe is rejected.
+	bad := binary.AppendUvarint(nil, 10)
+	for _, v := range []uint64{1, 1, 10, 1, 0, 1} { // 1 set, 1 column at 10
+		bad = binary.AppendUvarint(bad, v)
+	}
+	if _, err := (intset.Columnar[int]{Universe: 10}).Decode(bad); err == nil {
+		t.Error("Decode with column outside universe: got nil error")
+	}
+}
+
+func TestColumnarEncodeOutsideUniverse(t *testing.T) {
+	t.Parallel()
+
+	var s intset.IntSet[int]
+	s.AddAll(1, 10)
+
+	c := intset.Columnar[int]{Universe: 10}
+	if _, err := c.Encode([]*intset.IntSet[int]{&s}); err == nil {
+		t.Error("Encode with element outside universe: got nil error")
+	}
+}
+
+func TestColumnarUniverseOutOfRange(t *testing.T) {
+	t.Parallel()
+
+	c := intset.Columnar[uint64]{Universe: 1 << 63}
+	if _, err := c.Encode(nil); err == nil {
+		t.Error("Encode with universe 1<<63: got nil error")
+	}
+	if _, err := c.Decode([]byte{0, 0}); err == nil {
+		t.Error("Decode with universe 1<<63: got nil error")
+	}
+}
@@ -0,0 +1,384 @@
+package intset
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// The binary encoding of a set is a header of one byte holding the
+// word size in bits and the number of words as a little-endian uint64,
+// followed by the words of the set without trailing zero words, each
+// little-endian.
+const (
+	binaryHeaderLen = 1 + 8
+	wordBytes       = wordSize / 8
+)
+
+// MarshalBinary implements the encoding.BinaryMarshaler interface.
+func (s *IntSet[E]) MarshalBinary() ([]byte, error) {
+	words := s.liveWords()
+	return s.appendBinary(make([]byte, 0, binaryHeaderLen+len(words)*wordBytes)), nil
+}
+
+// appendBinary appends the binary encoding of the set s to b.
+func (s *IntSet[E]) appendBinary(b []byte) []byte {
+	words := s.liveWords()
+
+	b = appendBinaryHeader(b, len(words))
+	for _, w := range words {
+		b = appendWord(b, w)
+	}
+
+	return b
+}
+
+// appendBinaryHeader appends the header of the binary encoding of n
+// words to b.
+func appendBinaryHeader(b []byte, n int) []byte {
+	b = append(b, wordSize)
+	return binary.LittleEndian.AppendUint64(b, uint64(n))
+}
+
+// appendWord appends the little-endian encoding of w to b.
+func appendWord(b []byte, w uint) []byte {
+	if wordSize == 64 {
+		return binary.LittleEndian.AppendUint64(b, uint64(w))
+	}
+
+	return binary.LittleEndian.AppendUint32(b, uint32(w))
+}
+
+// WriteTo implements the io.WriterTo interface. It writes the same
+// encoding as MarshalBinary, in chunks of bounded size, and returns
+// the number of bytes written.
+func (s *IntSet[E]) WriteTo(w io.Writer) (int64, error) {
+	words := s.liveWords()
+
+	var buf [512]byte
+	b := appendBinaryHeader(buf[:0], len(words))
+
+	var total int64
+	for {
+		for len(words) > 0 && len(b)+wordBytes <= len(buf) {
+			b = appendWord(b, words[0])
+			words = words[1:]
+		}
+
+		n, err := w.Write(b)
+		total += int64(n)
+		if err != nil {
+			return total, err
+		}
+
+		if len(words) == 0 {
+			return total, nil
+		}
+		b = buf[:0]
+	}
+}
+
+// UnmarshalBinary implements the encoding.BinaryUnmarshaler interface.
+// It returns an error if data was encoded on a platform with a
+// different word size.
+func (s *IntSet[E]) UnmarshalBinary(data []byte) error {
+	n, err := parseBinaryHeader(data)
+	if err != nil {
+		return err
+	}
+
+	data = data[binaryHeaderLen:]
+	if uint64(len(data)) != n*wordBytes {
+		return errors.New("intset: binary data length does not match word count")
+	}
+
+	t := IntSet[E]{words: decodeWords(make([]uint, n), data)}
+	if !t.fitsElems() {
+		return errElemRange
+	}
+
+	s.words = t.liveWords()
+	return nil
+}
+
+// ReadFrom implements the io.ReaderFrom interface. It reads one set in
+// the encoding written by WriteTo from r, replacing the contents of s,
+// and returns the number of bytes read. It reads no further than the
+// end of the set, so several sets may be read in turn from one stream.
+//
+// As io.ReaderFrom requires, reaching EOF is not an error: if r is at
+// its end before the first byte, ReadFrom returns 0, nil and leaves s
+// unchanged. It returns an error wrapping io.ErrUnexpectedEOF if the
+// set is truncated.
+func (s *IntSet[E]) ReadFrom(r io.Reader) (int64, error) {
+	var buf [512]byte
+
+	total, err := io.ReadFull(r, buf[:binaryHeaderLen])
+	if err == io.EOF {
+		return 0, nil
+	}
+	if err != nil {
+		return int64(total), fmt.Errorf("intset: truncated binary header: %w", err)
+	}
+
+	n, err := parseBinaryHeader(buf[:binaryHeaderLen])
+	if err != nil {
+		return int64(total), err
+	}
+
+	// Grow words as data arrives rather than trusting n up front,
+	// and leave s unchanged if the data is truncated.
+	var words []uint
+	for remaining := n * wordBytes; remaining > 0; {
+		chunk := buf[:min(remaining, uint64(len(buf)))]
+		m, err := io.ReadFull(r, chunk)
+		total += m
+		if err != nil {
+			if err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+			return int64(total), fmt.Errorf("intset: truncated binary data: %w", err)
+		}
+
+		for i := 0; i < len(chunk); i += wordBytes {
+			words = append(words, 0)
+			decodeWords(words[len(words)-1:], chunk[i:])
+		}
+		remaining -= uint64(len(chunk))
+	}
+
+	t := IntSet[E]{words: words}
+	if !t.fitsElems() {
+		return int64(total), errElemRange
+	}
+
+	s.words = t.liveWords()
+	return int64(total), nil
+}
+
+// errElemRange is returned when decoding a set with an element that is
+// not a value of the element type.
+var errElemRange = errors.New("intset: element out of range of element type")
+
+// parseBinaryHeader checks the header of the binary encoding in data
+// and returns the number of words that follow it.
+func parseBinaryHeader(data []byte) (uint64, error) {
+	if len(data) < binaryHeaderLen {
+		return 0, errors.New("intset: binary data too short")
+	}
+
+	if data[0] != wordSize {
+		return 0, fmt.Errorf("intset: binary data has %d-bit words, want %d-bit", data[0], wordSize)
+	}
+
+	n := binary.LittleEndian.Uint64(data[1:])
+	if n > MaxInt/wordBytes {
+		return 0, errors.New("intset: binary data word count too large")
+	}
+
+	return n, nil
+}
+
+// decodeWords decodes len(words) little-endian words from data into words.
+func decodeWords(words []uint, data []byte) []uint {
+	for i := range words {
+		if wordSize == 64 {
+			words[i] = uint(binary.LittleEndian.Uint64(data[i*wordBytes:]))
+		} else {
+			words[i] = uint(binary.LittleEndian.Uint32(data[i*wordBytes:]))
+		}
+	}
+
+	return words
+}
+
+// MarshalText implements the encoding.TextMarshaler interface.
+// The set is encoded as its elements in order, separated by commas,
+// such as "1,9,144"; the empty set is encoded as no bytes.
+func (s *IntSet[E]) MarshalText() ([]byte, error) {
+	var b []byte
+	s.forEach(func(x E) {
+		if len(b) > 0 {
+			b = append(b, ',')
+		}
+
+		b = strconv.AppendInt(b, int64(x), 10)
+	})
+
+	return b, nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface.
+// It accepts the format produced by MarshalText, with optional spaces
+// around each element, in any order and with repetitions. On error
+// the set s is left unchanged.
+func (s *IntSet[E]) UnmarshalText(text []byte) error {
+	if len(bytes.TrimSpace(text)) == 0 {
+		s.Clear()
+		return nil
+	}
+
+	fields := bytes.Split(text, []byte(","))
+	xs := make([]E, 0, len(fields))
+	for _, field := range fields {
+		x, err := strconv.Atoi(string(bytes.TrimSpace(field)))
+		if err != nil {
+			return fmt.Errorf("intset: invalid element %q", field)
+		}
+
+		if x < 0 {
+			return fmt.Errorf("intset: negative value %d", x)
+		}
+
+		if !inRange[E](x) {
+			return fmt.Errorf("intset: value %d out of range", x)
+		}
+
+		xs = append(xs, E(x))
+	}
+
+	s.Clear()
+	for _, x := range xs {
+		s.Add(x)
+	}
+
+	return nil
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+// The set is encoded as an array of its elements in order.
+func (s *IntSet[E]) MarshalJSON() ([]byte, error) {
+	b := []byte{'['}
+	s.forEach(func(x E) {
+		if len(b) > len("[") {
+			b = append(b, ',')
+		}
+
+		b = strconv.AppendInt(b, int64(x), 10)
+	})
+	b = append(b, ']')
+
+	return b, nil
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+// It replaces the elements of the set s with those of a JSON array
+// of non-negative integers.
+func (s *IntSet[E]) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+
+	var xs []int
+	if err := json.Unmarshal(data, &xs); err != nil {
+		return err
+	}
+
+	for _, x := range xs {
+		if x < 0 {
+			return fmt.Errorf("intset: negative value %d", x)
+		}
+
+		if !inRange[E](x) {
+			return fmt.Errorf("intset: value %d out of range", x)
+		}
+	}
+
+	s.Clear()
+	for _, x := range xs {
+		s.Add(E(x))
+	}
+
+	return nil
+}
+
+// gobVersion is the version of the gob encoding of a set, which is
+// this byte followed by the binary encoding.
+const gobVersion = 1
+
+// GobEncode implements the gob.GobEncoder interface.
+func (s *IntSet[E]) GobEncode() ([]byte, error) {
+	words := s.liveWords()
+	return s.appendBinary(append(make([]byte, 0, 1+binaryHeaderLen+len(words)*wordBytes), gobVersion)), nil
+}
+
+// GobDecode implements the gob.GobDecoder interface.
+func (s *IntSet[E]) GobDecode(data []byte) error {
+	if len(data) == 0 {
+		return errors.New("intset: gob data too short")
+	}
+
+	if data[0] != gobVersion {
+		return fmt.Errorf("intset: unsupported gob version %d", data[0])
+	}
+
+	return s.UnmarshalBinary(data[1:])
+}
+
+// Parse parses a set in the format produced by String, such as
+// "{1 9 144}", for element types without a String method.
+func Parse[E Integer](s string) (*IntSet[E], error) {
+	inner, ok := strings.CutPrefix(s, "{")
+	if ok {
+		inner, ok = strings.CutSuffix(inner, "}")
+	}
+	if !ok {
+		return nil, fmt.Errorf("intset: parsing %q: missing braces", s)
+	}
+
+	set := &IntSet[E]{}
+	if inner == "" {
+		return set, nil
+	}
+
+	for _, field := range strings.Split(inner, " ") {
+		x, err := strconv.Atoi(field)
+		if err != nil {
+			return nil, fmt.Errorf("intset: parsing %q: invalid element %q", s, field)
+		}
+
+		if x < 0 {
+			return nil, fmt.Errorf("intset: parsing %q: negative value %d", s, x)
+		}
+
+		if !inRange[E](x) {
+			return nil, fmt.Errorf("intset: parsing %q: value %d out of range", s, x)
+		}
+
+		set.Add(E(x))
+	}
+
+	return set, nil
+}
+
+// ParseBitString parses a set in the format produced by BitString,
+// a string of 0s and 1s with the bit for element 0 last.
+func ParseBitString[E Integer](s string) (*IntSet[E], error) {
+	if s == "" {
+		return nil, errors.New("intset: parsing empty bit string")
+	}
+
+	// Leading 0s are padding, so only the positions of 1s need be
+	// values of E.
+	set := &IntSet[E]{}
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '0':
+		case '1':
+			x := len(s) - 1 - i
+			if !inRange[E](x) {
+				return nil, fmt.Errorf("intset: parsing %q: value %d out of range", s, x)
+			}
+			set.Add(E(x))
+		default:
+			return nil, fmt.Errorf("intset: parsing %q: invalid bit %q", s, s[i])
+		}
+	}
+
+	return set, nil
+}
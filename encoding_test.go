@@ -0,0 +1,494 @@
+package intset_test
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"errors"
+	"io"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/weiwenchen2022/intset"
+)
+
+func TestBinaryRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	r := rand.New(rand.NewSource(1))
+	sets := [][]int{nil, {0}, {1, 9, 144}}
+	for i := 0; i < 100; i++ {
+		sets = append(sets, randValues(r))
+	}
+
+	for _, xs := range sets {
+		var s intset.IntSet[int]
+		s.AddAll(xs...)
+
+		data, err := s.MarshalBinary()
+		if err != nil {
+			t.Fatalf("MarshalBinary: %v", err)
+		}
+
+		var got intset.IntSet[int]
+		got.AddAll(7, 5000) // overwritten by UnmarshalBinary
+		if err := got.UnmarshalBinary(data); err != nil {
+			t.Fatalf("UnmarshalBinary: %v", err)
+		}
+
+		if !got.Equals(&s) {
+			t.Fatalf("binary round trip: got %s, want %s", &got, &s)
+		}
+	}
+}
+
+func TestUnmarshalBinaryErrors(t *testing.T) {
+	t.Parallel()
+
+	var s intset.IntSet[int]
+	s.AddAll(1, 9, 144)
+
+	data, err := s.MarshalBinary()
+	if err != nil {
+		t.Fatalf("MarshalBinary: %v", err)
+	}
+
+	wordSize := 32 << (^uint(0) >> 63)
+	mismatched := append([]byte{byte(96 - wordSize)}, data[1:]...)
+
+	for _, tc := range []struct {
+		name string
+		data []byte
+	}{
+		{"empty", nil},
+		{"short header", data[:4]},
+		{"truncated", data[:len(data)-1]},
+		{"trailing data", append(data[:len(data):len(data)], 0)},
+		{"word size mismatch", mismatched},
+	} {
+		var got intset.IntSet[int]
+		if err := got.UnmarshalBinary(tc.data); err == nil {
+			t.Errorf("UnmarshalBinary(%s): got nil error", tc.name)
+		}
+	}
+}
+
+func TestWriteTo(t *testing.T) {
+	t.Parallel()
+
+	r := rand.New(rand.NewSource(1))
+	sets := [][]int{nil, {0}, {1, 9, 144}, {1 << 16}}
+	for i := 0; i < 100; i++ {
+		sets = append(sets, randValues(r))
+	}
+
+	for _, xs := range sets {
+		var s intset.IntSet[int]
+		s.AddAll(xs...)
+
+		var buf bytes.Buffer
+		n, err := s.WriteTo(&buf)
+		if err != nil {
+			t.Fatalf("WriteTo: %v", err)
+		}
+		if n != int64(buf.Len()) {
+			t.Errorf("WriteTo: returned %d, wrote %d bytes", n, buf.Len())
+		}
+
+		want, _ := s.MarshalBinary()
+		if !bytes.Equal(buf.Bytes(), want) {
+			t.Fatalf("WriteTo: got %x, want %x", buf.Bytes(), want)
+		}
+	}
+}
+
+type shortWriter struct{ n int }
+
+func (w *shortWriter) Write(p []byte) (int, error) {
+	if len(p) > w.n {
+		n := w.n
+		w.n = 0
+		return n, errors.New("short write")
+	}
+
+	w.n -= len(p)
+	return len(p), nil
+}
+
+func TestWriteToError(t *testing.T) {
+	t.Parallel()
+
+	var s intset.IntSet[int]
+	s.AddAll(1, 9, 1<<16)
+
+	w := &shortWriter{n: 600}
+	n, err := s.WriteTo(w)
+	if err == nil {
+		t.Fatal("WriteTo: got nil error")
+	}
+	if n != 600 {
+		t.Errorf("WriteTo: got %d bytes written, want 600", n)
+	}
+}
+
+func TestReadFrom(t *testing.T) {
+	t.Parallel()
+
+	r := rand.New(rand.NewSource(1))
+	sets := [][]int{nil, {0}, {1, 9, 144}, {1 << 16}}
+	for i := 0; i < 100; i++ {
+		sets = append(sets, randValues(r))
+	}
+
+	pr, pw := io.Pipe()
+	go func() {
+		for _, xs := range sets {
+			var s intset.IntSet[int]
+			s.AddAll(xs...)
+
+			if _, err := s.WriteTo(pw); err != nil {
+				pw.CloseWithError(err)
+				return
+			}
+		}
+		pw.Close()
+	}()
+
+	for _, xs := range sets {
+		var want intset.IntSet[int]
+		want.AddAll(xs...)
+		data, _ := want.MarshalBinary()
+
+		var got intset.IntSet[int]
+		got.AddAll(7, 5000) // overwritten by ReadFrom
+		n, err := got.ReadFrom(pr)
+		if err != nil {
+			t.Fatalf("ReadFrom: %v", err)
+		}
+		if n != int64(len(data)) {
+			t.Errorf("ReadFrom: got %d bytes read, want %d", n, len(data))
+		}
+		if !got.Equals(&want) {
+			t.Fatalf("ReadFrom: got %s, want %s", &got, &want)
+		}
+	}
+
+	var s intset.IntSet[int]
+	s.Add(3)
+	if n, err := s.ReadFrom(pr); n != 0 || err != nil {
+		t.Errorf("ReadFrom at end of stream: got %d, %v, want 0, nil", n, err)
+	}
+	if got := s.String(); got != "{3}" {
+		t.Errorf("ReadFrom at end of stream: set changed to %s", got)
+	}
+}
+
+func TestReadFromCopy(t *testing.T) {
+	t.Parallel()
+
+	var want intset.IntSet[int]
+	want.AddAll(1, 9, 144)
+	data, _ := want.MarshalBinary()
+
+	// io.Copy takes an io.Writer, and uses its ReadFrom method if it
+	// has one; hide bytes.Reader's WriteTo so that it does.
+	var got intset.IntSet[int]
+	dst := struct {
+		io.ReaderFrom
+		io.Writer
+	}{ReaderFrom: &got}
+
+	n, err := io.Copy(dst, struct{ io.Reader }{bytes.NewReader(data)})
+	if err != nil {
+		t.Fatalf("io.Copy: %v", err)
+	}
+	if n != int64(len(data)) {
+		t.Errorf("io.Copy: got %d bytes, want %d", n, len(data))
+	}
+	if !got.Equals(&want) {
+		t.Errorf("io.Copy: got %s, want %s", &got, &want)
+	}
+
+	if n, err := io.Copy(dst, struct{ io.Reader }{bytes.NewReader(nil)}); n != 0 || err != nil {
+		t.Errorf("io.Copy from an empty stream: got %d, %v, want 0, nil", n, err)
+	}
+}
+
+func TestReadFromErrors(t *testing.T) {
+	t.Parallel()
+
+	var s intset.IntSet[int]
+	s.AddAll(1, 9, 1<<16)
+
+	data, err := s.MarshalBinary()
+	if err != nil {
+		t.Fatalf("MarshalBinary: %v", err)
+	}
+
+	wordSize := 32 << (^uint(0) >> 63)
+	mismatched := append([]byte{byte(96 - wordSize)}, data[1:]...)
+
+	for _, tc := range []struct {
+		name      string
+		data      []byte
+		truncated bool
+	}{
+		{"short header", data[:4], true},
+		{"truncated", data[:len(data)-1], true},
+		{"header only", data[:9], true},
+		{"word size mismatch", mismatched, false},
+	} {
+		got := intset.FromBits[int](0b101)
+		n, err := got.ReadFrom(bytes.NewReader(tc.data))
+		if err == nil {
+			t.Errorf("ReadFrom(%s): got nil error", tc.name)
+			continue
+		}
+		if tc.truncated && !errors.Is(err, io.ErrUnexpectedEOF) {
+			t.Errorf("ReadFrom(%s): got %v, want io.ErrUnexpectedEOF", tc.name, err)
+		}
+		if tc.truncated && n != int64(len(tc.data)) {
+			t.Errorf("ReadFrom(%s): got %d bytes read, want %d", tc.name, n, len(tc.data))
+		}
+		if want := "{0 2}"; got.String() != want {
+			t.Errorf("ReadFrom(%s): set changed to %s", tc.name, got)
+		}
+	}
+}
+
+func TestTextRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	r := rand.New(rand.NewSource(1))
+	sets := [][]int{nil, {0}, {1, 9, 144}}
+	for i := 0; i < 100; i++ {
+		sets = append(sets, randValues(r))
+	}
+
+	for _, xs := range sets {
+		var s intset.IntSet[int]
+		s.AddAll(xs...)
+
+		text, err := s.MarshalText()
+		if err != nil {
+			t.Fatalf("MarshalText: %v", err)
+		}
+
+		var got intset.IntSet[int]
+		got.AddAll(7, 5000) // overwritten by UnmarshalText
+		if err := got.UnmarshalText(text); err != nil {
+			t.Fatalf("UnmarshalText(%q): %v", text, err)
+		}
+
+		if !got.Equals(&s) {
+			t.Fatalf("text round trip: got %s, want %s", &got, &s)
+		}
+	}
+}
+
+func TestText(t *testing.T) {
+	t.Parallel()
+
+	var s intset.IntSet[int]
+	if text, _ := s.MarshalText(); len(text) != 0 {
+		t.Errorf("{}.MarshalText: got %q, want empty", text)
+	}
+
+	s.AddAll(144, 1, 9)
+	if text, _ := s.MarshalText(); string(text) != "1,9,144" {
+		t.Errorf("%s.MarshalText: got %q, want %q", &s, text, "1,9,144")
+	}
+
+	for _, tc := range []struct {
+		text string
+		want string
+	}{
+		{"", "{}"},
+		{"  ", "{}"},
+		{" 144 , 1,9 ", "{1 9 144}"},
+		{"9,9,1,9", "{1 9}"},
+	} {
+		var got intset.IntSet[int]
+		if err := got.UnmarshalText([]byte(tc.text)); err != nil {
+			t.Errorf("UnmarshalText(%q): %v", tc.text, err)
+		} else if got.String() != tc.want {
+			t.Errorf("UnmarshalText(%q): got %s, want %s", tc.text, &got, tc.want)
+		}
+	}
+
+	for _, text := range []string{"1,,2", "abc", "1,abc", "1,2,", ",1", "1 2", "-1", "1.5"} {
+		var got intset.IntSet[int]
+		got.AddAll(7, 5000)
+		if err := got.UnmarshalText([]byte(text)); err == nil {
+			t.Errorf("UnmarshalText(%q): got nil error", text)
+		} else if got.String() != "{7 5000}" {
+			t.Errorf("UnmarshalText(%q): set changed to %s on error", text, &got)
+		}
+	}
+}
+
+func TestJSON(t *testing.T) {
+	t.Parallel()
+
+	type doc struct {
+		Name string
+		Set  intset.IntSet[int]
+		Ptr  *intset.IntSet[int] `json:",omitempty"`
+	}
+
+	var in doc
+	in.Name = "x"
+	in.Set.AddAll(144, 1, 9)
+	in.Ptr = new(intset.IntSet[int])
+
+	data, err := json.Marshal(&in)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+
+	if want := `{"Name":"x","Set":[1,9,144],"Ptr":[]}`; string(data) != want {
+		t.Errorf("Marshal: got %s, want %s", data, want)
+	}
+
+	var out doc
+	out.Set.Add(7) // replaced by Unmarshal
+	if err := json.Unmarshal(data, &out); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+
+	if !out.Set.Equals(&in.Set) || out.Ptr == nil || !out.Ptr.IsEmpty() {
+		t.Errorf("Unmarshal: got %s and %v, want %s and {}", &out.Set, out.Ptr, &in.Set)
+	}
+
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 100; i++ {
+		var s, got intset.IntSet[int]
+		s.AddAll(randValues(r)...)
+
+		data, err := json.Marshal(&s)
+		if err != nil {
+			t.Fatalf("Marshal: %v", err)
+		}
+		if err := json.Unmarshal(data, &got); err != nil {
+			t.Fatalf("Unmarshal(%s): %v", data, err)
+		}
+
+		if !got.Equals(&s) {
+			t.Fatalf("JSON round trip: got %s, want %s", &got, &s)
+		}
+	}
+
+	for _, data := range []string{`[1,-2]`, `["a"]`, `{}`, `[1.5]`} {
+		var got intset.IntSet[int]
+		if err := json.Unmarshal([]byte(data), &got); err == nil {
+			t.Errorf("Unmarshal(%s): got nil error", data)
+		}
+	}
+}
+
+func TestGob(t *testing.T) {
+	t.Parallel()
+
+	r := rand.New(rand.NewSource(1))
+	sets := []*intset.IntSet[int]{new(intset.IntSet[int])}
+	for i := 0; i < 20; i++ {
+		s := new(intset.IntSet[int])
+		s.AddAll(randValues(r)...)
+		sets = append(sets, s)
+	}
+
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(sets); err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+
+	var got []*intset.IntSet[int]
+	if err := gob.NewDecoder(&buf).Decode(&got); err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+
+	if len(got) != len(sets) {
+		t.Fatalf("Decode: got %d sets, want %d", len(got), len(sets))
+	}
+
+	for i := range sets {
+		if !got[i].Equals(sets[i]) {
+			t.Errorf("Decode #%d: got %s, want %s", i, got[i], sets[i])
+		}
+	}
+
+	var s intset.IntSet[int]
+	if err := s.GobDecode([]byte{0}); err == nil {
+		t.Error("GobDecode with bad version: got nil error")
+	}
+}
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+
+	r := rand.New(rand.NewSource(1))
+	sets := [][]int{nil, {0}, {1, 9, 144}}
+	for i := 0; i < 100; i++ {
+		sets = append(sets, randValues(r))
+	}
+
+	for _, xs := range sets {
+		var s intset.IntSet[int]
+		s.AddAll(xs...)
+
+		got, err := intset.Parse[int](s.String())
+		if err != nil {
+			t.Fatalf("Parse(%q): %v", &s, err)
+		}
+
+		if !got.Equals(&s) {
+			t.Fatalf("Parse(%q): got %s", &s, got)
+		}
+	}
+
+	for _, s := range []string{"", "1 2", "{1 2", "1 2}", "{a}", "{-1}", "{1  2}", "{ 1}", "{1,2}"} {
+		if got, err := intset.Parse[int](s); err == nil {
+			t.Errorf("Parse(%q): got %s, want error", s, got)
+		}
+	}
+}
+
+func TestParseBitString(t *testing.T) {
+	t.Parallel()
+
+	r := rand.New(rand.NewSource(1))
+	sets := [][]int{nil, {0}, {0, 4, 5}, {0, 7, 177}}
+	for i := 0; i < 100; i++ {
+		sets = append(sets, randValues(r))
+	}
+
+	for _, xs := range sets {
+		var s intset.IntSet[int]
+		s.AddAll(xs...)
+
+		got, err := intset.ParseBitString[int](s.BitString())
+		if err != nil {
+			t.Fatalf("ParseBitString(%q): %v", s.BitString(), err)
+		}
+
+		if !got.Equals(&s) {
+			t.Fatalf("ParseBitString(%q): got %s, want %s", s.BitString(), got, &s)
+		}
+	}
+
+	for _, s := range []string{"", "012", "1 0", "abc"} {
+		if got, err := intset.ParseBitString[int](s); err == nil {
+			t.Errorf("ParseBitString(%q): got %s, want error", s, got)
+		}
+	}
+
+	// Leading 0s are padding and need not fit in the element type.
+	padded := strings.Repeat("0", 299) + "1"
+	if got, err := intset.ParseBitString[uint8](padded); err != nil || got.String() != "{0}" {
+		t.Errorf("ParseBitString[uint8] of padded {0}: got %v, %v, want {0}, nil", got, err)
+	}
+	if got, err := intset.ParseBitString[uint8]("1" + padded); err == nil {
+		t.Errorf("ParseBitString[uint8] with bit 300 set: got %s, want error", got)
+	}
+}
@@ -0,0 +1,11 @@
+package intset
+
+// WordsCap returns the capacity of the words backing the set s.
+func WordsCap[E Integer](s *IntSet[E]) int {
+	return cap(s.words)
+}
+
+// SetWords sets the words backing the set s, which need not be trimmed.
+func SetWords[E Integer](s *IntSet[E], words []uint) {
+	s.words = words
+}
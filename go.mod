@@ -1,5 +1,5 @@
 module github.com/weiwenchen2022/intset
 
-go 1.20
+go 1.23
 
 require github.com/google/go-cmp v0.5.9
@@ -1,17 +1,21 @@
 // Package intset provides IntSet, a compact and fast representation
-// for sets of non-negative int values.
+// for sets of small non-negative integer values.
 //
 // The time complexity of the operations Add, Remove and Has
 // is in O(1) in practice those methods are faster and more
 // space-efficient than equivalent operations on sets based on the Go
-// map type. The Len, IsEmpty, Min, Max, and TakeMin operations
+// map type. The Len, IsEmpty, Min, Max, TakeMin and TakeMax operations
 // require O(n).
 package intset
 
 import (
 	"fmt"
+	"iter"
+	"math"
+	"math/big"
 	"math/bits"
-	"strings"
+	"slices"
+	"strconv"
 	"unsafe"
 )
 
@@ -22,6 +26,18 @@ const (
 	MinInt  = -MaxInt - 1
 )
 
+// Integer is a constraint that permits any integer type, the types of
+// the elements of an IntSet.
+//
+// Whatever the type, elements are non-negative, and values passed to
+// methods must fit in an int; methods panic on values that do not.
+// Half-open intervals [lo, hi) of the type cannot include its largest
+// value.
+type Integer interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 |
+		~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~uintptr
+}
+
 const (
 	wordSize    = 32 << (^uint(0) >> 63)
 	lg2WordSize = 4 + 1<<(^uint(0)>>63)
@@ -40,25 +56,136 @@ func wordBit(x int) (w int, bit uint) {
 	return x >> lg2WordSize, uint(x & bitmask)
 }
 
-// IntSet is a set of small non-negative int values.
+// IntSet is a set of small non-negative integer values.
 //
 // The zero value represents a valid empty set.
 //
-// IntSet must be copied using the Copy method, not by assigning
-// a IntSet value.
-type IntSet[E ~int] struct {
+// IntSet must be copied using the Copy or Clone method, not by assigning
+// a IntSet value: an assigned copy shares storage with the original, so
+// mutating either may corrupt the other.
+type IntSet[E Integer] struct {
 	words []uint
 }
 
+// NewWithCapacity returns an empty set with room for values up to
+// maxValue, so that adding them requires no further allocation.
+// It panics if maxValue is negative.
+func NewWithCapacity[E Integer](maxValue E) *IntSet[E] {
+	s := &IntSet[E]{}
+	s.Grow(maxValue)
+
+	return s
+}
+
+// FromBits returns a new set holding i for each set bit i of x.
+func FromBits[E Integer](x uint64) *IntSet[E] {
+	s := &IntSet[E]{}
+	for ; x != 0; x &= x - 1 {
+		s.Add(E(bits.TrailingZeros64(x)))
+	}
+
+	return s
+}
+
+// FromWords returns a new set whose representation is a copy of words,
+// in the layout returned by Words, so that bit i of words[j] stands for
+// the value j*bits.UintSize + i. It panics if a set bit stands for a
+// value too large for E.
+func FromWords[E Integer](words []uint) *IntSet[E] {
+	live := (&IntSet[E]{words: words}).liveWords()
+
+	s := &IntSet[E]{}
+	if len(live) > 0 {
+		s.words = make([]uint, len(live))
+		copy(s.words, live)
+	}
+
+	s.mustFitElems()
+	return s
+}
+
+// FromSlice returns a new set holding the non-negative values xs, as
+// by AddSlice. It panics if any value in xs is negative.
+func FromSlice[E Integer](xs []E) *IntSet[E] {
+	s := &IntSet[E]{}
+	s.AddSlice(xs)
+
+	return s
+}
+
+// FromBools returns a new set holding i for each true element mask[i].
+// It panics if such an i is too large for E.
+func FromBools[E Integer](mask []bool) *IntSet[E] {
+	s := &IntSet[E]{}
+	if len(mask) == 0 {
+		return s
+	}
+
+	s.words = make([]uint, (len(mask)-1)/wordSize+1)
+	for i, b := range mask {
+		if b {
+			w, m := wordMask(i)
+			s.words[w] |= m
+		}
+	}
+
+	s.trim()
+	s.mustFitElems()
+	return s
+}
+
+// FromBigInt returns a new set holding x for each set bit x of z.
+// It panics if z is negative or has a set bit x too large for E.
+func FromBigInt[E Integer](z *big.Int) *IntSet[E] {
+	if z.Sign() < 0 {
+		panic(fmt.Sprintf("intset: negative big.Int %s", z))
+	}
+
+	zwords := z.Bits()
+	s := &IntSet[E]{words: make([]uint, len(zwords))}
+	for i, w := range zwords {
+		s.words[i] = uint(w)
+	}
+
+	s.mustFitElems()
+	return s
+}
+
 // Has reports whether the set s contains the non-negative value x.
+// It panics if x is negative.
 func (s *IntSet[E]) Has(x E) bool {
-	w, mask := wordMask(int(x))
+	w, mask := wordMask(toInt(x))
 	return w < len(s.words) && s.words[w]&mask != 0
 }
 
+// ContainsAll reports whether the set s contains every value in xs.
+// It returns true if xs is empty.
+func (s *IntSet[E]) ContainsAll(xs ...E) bool {
+	for _, x := range xs {
+		if !s.Has(x) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// ContainsAny reports whether the set s contains at least one value in xs.
+// It returns false if xs is empty.
+func (s *IntSet[E]) ContainsAny(xs ...E) bool {
+	for _, x := range xs {
+		if s.Has(x) {
+			return true
+		}
+	}
+
+	return false
+}
+
 // Add adds the non-negative value x to the set s, and reports whether the set grew.
+// It panics if x is negative.
 func (s *IntSet[E]) Add(x E) bool {
-	w, mask := wordMask(int(x))
+	w, mask := wordMask(toInt(x))
 
 	if w < len(s.words) && s.words[w]&mask != 0 {
 		return false
@@ -72,6 +199,23 @@ func (s *IntSet[E]) Add(x E) bool {
 	return true
 }
 
+// AddIf adds the non-negative value x to the set s if cond is true, and
+// reports whether x was in the set before the call.
+// It panics if x is negative.
+func (s *IntSet[E]) AddIf(x E, cond bool) bool {
+	w, mask := wordMask(toInt(x))
+	if w < len(s.words) && s.words[w]&mask != 0 {
+		return true
+	}
+
+	if cond {
+		s.extend(w + 1)
+		s.words[w] |= mask
+	}
+
+	return false
+}
+
 // AddAll adds a group of non-negative value xs to the set.
 func (s *IntSet[E]) AddAll(xs ...E) {
 	for _, x := range xs {
@@ -79,9 +223,84 @@ func (s *IntSet[E]) AddAll(xs ...E) {
 	}
 }
 
+// AddSlice adds the non-negative values xs to the set s. Unlike AddAll,
+// it finds the maximum of xs first so as to grow the set at most once.
+// It panics if any value in xs is negative.
+func (s *IntSet[E]) AddSlice(xs []E) {
+	if len(xs) == 0 {
+		return
+	}
+
+	hi := xs[0]
+	for _, x := range xs {
+		if x < 0 {
+			negativeValue(x)
+		}
+
+		if x > hi {
+			hi = x
+		}
+	}
+
+	w, _ := wordBit(toInt(hi))
+	s.extend(w + 1)
+
+	for _, x := range xs {
+		w, mask := wordMask(toInt(x))
+		s.words[w] |= mask
+	}
+}
+
+// AddRange adds the values in [lo, hi) to the set s.
+// It does nothing if lo >= hi, and panics if lo is negative.
+func (s *IntSet[E]) AddRange(lo, hi E) {
+	if lo >= hi {
+		return
+	}
+
+	if lo < 0 {
+		negativeValue(lo)
+	}
+
+	lw, lbit := wordBit(toInt(lo))
+	hw, hbit := wordBit(toInt(hi) - 1)
+	s.extend(hw + 1)
+
+	if lw == hw {
+		s.words[lw] |= bitRange(lbit, hbit)
+		return
+	}
+
+	s.words[lw] |= bitRange(lbit, wordSize-1)
+	for i := lw + 1; i < hw; i++ {
+		s.words[i] = ^uint(0)
+	}
+	s.words[hw] |= bitRange(0, hbit)
+}
+
 // Remove remove x from the set s, and reports whether the set shrank.
+// It panics if x is negative.
 func (s *IntSet[E]) Remove(x E) bool {
-	w, mask := wordMask(int(x))
+	if !s.remove(x) {
+		return false
+	}
+
+	s.trim()
+	return true
+}
+
+// RemoveAll removes a group of values xs from the set.
+func (s *IntSet[E]) RemoveAll(xs ...E) {
+	for _, x := range xs {
+		s.remove(x)
+	}
+
+	s.trim()
+}
+
+// remove is like Remove but leaves trailing zero words in place.
+func (s *IntSet[E]) remove(x E) bool {
+	w, mask := wordMask(toInt(x))
 	if w >= len(s.words) || s.words[w]&mask == 0 {
 		return false
 	}
@@ -90,6 +309,115 @@ func (s *IntSet[E]) Remove(x E) bool {
 	return true
 }
 
+// RemoveRange removes the values in [lo, hi) from the set s.
+// It does nothing if lo >= hi, and panics if lo is negative.
+func (s *IntSet[E]) RemoveRange(lo, hi E) {
+	if lo >= hi {
+		return
+	}
+
+	if lo < 0 {
+		negativeValue(lo)
+	}
+
+	lw, lbit := wordBit(toInt(lo))
+	if lw >= len(s.words) {
+		return
+	}
+
+	hw, hbit := wordBit(toInt(hi) - 1)
+	if hw >= len(s.words) {
+		hw, hbit = len(s.words)-1, wordSize-1
+	}
+
+	if lw == hw {
+		s.words[lw] &^= bitRange(lbit, hbit)
+	} else {
+		s.words[lw] &^= bitRange(lbit, wordSize-1)
+		for i := lw + 1; i < hw; i++ {
+			s.words[i] = 0
+		}
+		s.words[hw] &^= bitRange(0, hbit)
+	}
+
+	s.trim()
+}
+
+// ClearBelow removes the elements less than x from the set s.
+// It panics if x is negative.
+func (s *IntSet[E]) ClearBelow(x E) {
+	if x < 0 {
+		negativeValue(x)
+	}
+
+	w, bit := wordBit(toInt(x))
+	if w >= len(s.words) {
+		s.Reset()
+		return
+	}
+
+	clear(s.words[:w])
+	s.words[w] &^= 1<<bit - 1
+	s.trim()
+}
+
+// ClearAbove removes the elements greater than or equal to x from the
+// set s. It panics if x is negative.
+func (s *IntSet[E]) ClearAbove(x E) {
+	if x < 0 {
+		negativeValue(x)
+	}
+
+	w, bit := wordBit(toInt(x))
+	if w >= len(s.words) {
+		return
+	}
+
+	s.words[w] &= 1<<bit - 1
+	clear(s.words[w+1:])
+	s.words = s.words[:w+1]
+	s.trim()
+}
+
+// Toggle adds the non-negative value x to the set s if it is absent and
+// removes it otherwise, and reports whether x is now in the set.
+func (s *IntSet[E]) Toggle(x E) bool {
+	if s.Remove(x) {
+		return false
+	}
+
+	s.Add(x)
+	return true
+}
+
+// ToggleRange toggles the membership of each value in [lo, hi) in the
+// set s. It does nothing if lo >= hi, and panics if lo is negative.
+func (s *IntSet[E]) ToggleRange(lo, hi E) {
+	if lo >= hi {
+		return
+	}
+
+	if lo < 0 {
+		negativeValue(lo)
+	}
+
+	lw, lbit := wordBit(toInt(lo))
+	hw, hbit := wordBit(toInt(hi) - 1)
+	s.extend(hw + 1)
+
+	if lw == hw {
+		s.words[lw] ^= bitRange(lbit, hbit)
+	} else {
+		s.words[lw] ^= bitRange(lbit, wordSize-1)
+		for i := lw + 1; i < hw; i++ {
+			s.words[i] = ^s.words[i]
+		}
+		s.words[hw] ^= bitRange(0, hbit)
+	}
+
+	s.trim()
+}
+
 // Len return the number of elements
 func (s *IntSet[E]) Len() int {
 	n := 0
@@ -105,6 +433,12 @@ func (s *IntSet[E]) Len() int {
 	return n
 }
 
+// Cardinality returns the number of elements of the set s.
+// It is the same as Len.
+func (s *IntSet[E]) Cardinality() int {
+	return s.Len()
+}
+
 // IsEmpty reports whether the set s is empty.
 func (s *IntSet[E]) IsEmpty() bool {
 	for _, w := range s.words {
@@ -116,6 +450,125 @@ func (s *IntSet[E]) IsEmpty() bool {
 	return true
 }
 
+// BlockFillRatios splits [0, Max()] into consecutive blocks of
+// blockSize values and returns, for each block, the fraction of its
+// values that are in the set s. It returns nil if s is empty and panics
+// if blockSize is not positive.
+func (s *IntSet[E]) BlockFillRatios(blockSize E) []float64 {
+	if blockSize <= 0 {
+		panic(fmt.Sprintf("intset: non-positive block size %d", int(blockSize)))
+	}
+
+	if s.IsEmpty() {
+		return nil
+	}
+
+	size := toInt(blockSize)
+	ratios := make([]float64, int(s.Max())/size+1)
+	for i := range ratios {
+		lo := i * size
+		hi := lo + size
+		if hi < lo {
+			hi = MaxInt
+		}
+
+		ratios[i] = float64(s.countRange(lo, hi)) / float64(size)
+	}
+
+	return ratios
+}
+
+// Count returns the number of elements of the set s in [lo, hi).
+// It returns 0 if lo >= hi, and panics if lo is negative.
+func (s *IntSet[E]) Count(lo, hi E) int {
+	if lo >= hi {
+		return 0
+	}
+
+	if lo < 0 {
+		negativeValue(lo)
+	}
+
+	return s.countRange(toInt(lo), toInt(hi))
+}
+
+// IntersectsRange reports whether the set s has an element in [lo, hi).
+// It returns false if lo >= hi, and panics if lo is negative.
+func (s *IntSet[E]) IntersectsRange(lo, hi E) bool {
+	if lo >= hi {
+		return false
+	}
+
+	if lo < 0 {
+		negativeValue(lo)
+	}
+
+	lw, lbit := wordBit(toInt(lo))
+	if lw >= len(s.words) {
+		return false
+	}
+
+	hw, hbit := wordBit(toInt(hi) - 1)
+	if hw >= len(s.words) {
+		hw, hbit = len(s.words)-1, wordSize-1
+	}
+
+	if lw == hw {
+		return s.words[lw]&bitRange(lbit, hbit) != 0
+	}
+
+	if s.words[lw]&bitRange(lbit, wordSize-1) != 0 {
+		return true
+	}
+
+	for _, w := range s.words[lw+1 : hw] {
+		if w != 0 {
+			return true
+		}
+	}
+
+	return s.words[hw]&bitRange(0, hbit) != 0
+}
+
+// countRange returns the number of elements of the set s in [lo, hi),
+// where lo is non-negative.
+func (s *IntSet[E]) countRange(lo, hi int) int {
+	if lo >= hi {
+		return 0
+	}
+
+	lw, lbit := wordBit(lo)
+	if lw >= len(s.words) {
+		return 0
+	}
+
+	hw, hbit := wordBit(hi - 1)
+	if hw >= len(s.words) {
+		hw, hbit = len(s.words)-1, wordSize-1
+	}
+
+	if lw == hw {
+		return popcount(s.words[lw] & bitRange(lbit, hbit))
+	}
+
+	n := popcount(s.words[lw] & bitRange(lbit, wordSize-1))
+	for _, w := range s.words[lw+1 : hw] {
+		n += popcount(w)
+	}
+
+	return n + popcount(s.words[hw]&bitRange(0, hbit))
+}
+
+// IsDense reports whether the occupancy Len()/(Max()+1) of the set s
+// is at least threshold. It returns false if s is empty.
+func (s *IntSet[E]) IsDense(threshold float64) bool {
+	if s.IsEmpty() {
+		return false
+	}
+
+	return float64(s.Len())/float64(int(s.Max())+1) >= threshold
+}
+
 // AppendTo returns the result of appending the elements of s to slice in order.
 func (s *IntSet[E]) AppendTo(slice []E) []E {
 	total := len(slice) + s.Len()
@@ -136,6 +589,10 @@ func (s *IntSet[E]) AppendTo(slice []E) []E {
 }
 
 // Elems return the elements of the set s in order.
+//
+// It counts the elements before collecting them, so that the result
+// is allocated once at its exact size; on large sets the counting pass
+// costs much less than growing the result as it is filled.
 func (s *IntSet[E]) Elems() []E {
 	return s.AppendTo(nil)
 }
@@ -163,11 +620,139 @@ func (s *IntSet[E]) TakeMin(p *E) bool {
 	return false
 }
 
+// Pop removes the minimum element of the set s and returns it, together
+// with true, if s is non-empty. Otherwise, it returns 0 and false.
+func (s *IntSet[E]) Pop() (E, bool) {
+	var x E
+	if !s.TakeMin(&x) {
+		return 0, false
+	}
+
+	return x, true
+}
+
+// TakeMax sets *p to the maximum element of the set s,
+// removes that element from the set and returns true if set s is non-empty.
+// Otherwise, it returns false and *p is undefined.
+func (s *IntSet[E]) TakeMax(p *E) bool {
+	for i := len(s.words) - 1; i > -1; i-- {
+		w := s.words[i]
+		if w == 0 {
+			continue
+		}
+
+		j := wordSize - nlz(w) - 1
+		s.words[i] &^= 1 << uint(j)
+		*p = E(wordSize*i + j)
+		return true
+	}
+
+	return false
+}
+
+// PopMax removes the maximum element of the set s and returns it,
+// together with true, if s is non-empty. Otherwise, it returns 0 and
+// false. It trims the words emptied by the removal, so that draining
+// a set with PopMax takes time linear in its size.
+func (s *IntSet[E]) PopMax() (E, bool) {
+	var x E
+	if !s.TakeMax(&x) {
+		return 0, false
+	}
+
+	s.trim()
+	return x, true
+}
+
 // Clear remove all elements from the set s.
 func (s *IntSet[E]) Clear() {
 	s.words = nil
 }
 
+// Reset removes all elements from the set s but, unlike Clear, keeps
+// the backing storage so that refilling s need not allocate.
+func (s *IntSet[E]) Reset() {
+	clear(s.words)
+	s.words = s.words[:0]
+}
+
+// Grow grows the capacity of the set s, if necessary, so that values
+// up to n can be added without another allocation. It does not change
+// the elements of s, and panics if n is negative.
+func (s *IntSet[E]) Grow(n E) {
+	if n < 0 {
+		negativeValue(n)
+	}
+
+	w, _ := wordBit(toInt(n))
+	if w < cap(s.words) {
+		return
+	}
+
+	words := make([]uint, len(s.words), w+1)
+	copy(words, s.words)
+	s.words = words
+}
+
+// Shrink trims trailing zero words from the set s and, if what remains
+// fills no more than a quarter of the backing array, moves it to a
+// right-sized one so the excess memory can be garbage collected.
+//
+// Shrink is never called implicitly, since reusing spare capacity is
+// what keeps repeated growth cheap; call it on long-lived sets after
+// removing large elements.
+func (s *IntSet[E]) Shrink() {
+	s.trim()
+
+	switch n := len(s.words); {
+	case n == 0:
+		s.words = nil
+	case n <= cap(s.words)/4:
+		words := make([]uint, n)
+		copy(words, s.words)
+		s.words = words
+	}
+}
+
+// Stats describes the storage used by a set.
+type Stats struct {
+	Elements int     // number of elements
+	Words    int     // number of words in use, including trailing zero words
+	Bytes    int     // size in bytes of the backing array
+	Density  float64 // fraction of the bits of the words in use that are set
+}
+
+// Stats returns statistics on the storage used by the set s, which may
+// help decide when to call Shrink.
+func (s *IntSet[E]) Stats() Stats {
+	st := Stats{
+		Words: len(s.words),
+		Bytes: cap(s.words) * wordBytes,
+	}
+
+	for _, w := range s.words {
+		st.Elements += popcount(w)
+	}
+
+	if st.Words > 0 {
+		st.Density = float64(st.Elements) / float64(st.Words*wordSize)
+	}
+
+	return st
+}
+
+// WordCount returns the number of words needed to represent the set s,
+// that is, the index of the word holding Max plus one, or 0 if s is empty.
+func (s *IntSet[E]) WordCount() int {
+	return len(s.liveWords())
+}
+
+// CapBits returns the number of bits in the backing storage of the
+// set s, which bounds the values it can hold without allocating.
+func (s *IntSet[E]) CapBits() int {
+	return cap(s.words) * wordSize
+}
+
 // Copy return a copy of the set s.
 func (s *IntSet[E]) Copy() *IntSet[E] {
 	sc := &IntSet[E]{
@@ -178,26 +763,278 @@ func (s *IntSet[E]) Copy() *IntSet[E] {
 	return sc
 }
 
-// String returns a human-readable description of the set s.
-func (s *IntSet[E]) String() string {
-	var b strings.Builder
+// Clone returns a copy of the set s. It is the same as Copy, named
+// after the Clone functions of the standard library.
+func (s *IntSet[E]) Clone() *IntSet[E] {
+	return s.Copy()
+}
 
-	b.WriteByte('{')
-	s.forEach(func(x E) {
-		if b.Len() > len("{") {
-			b.WriteByte(' ')
-		}
+// CopyInto overwrites dst with the contents of s, reusing the storage
+// of dst when it is large enough. Any previous elements of dst are lost.
+func (s *IntSet[E]) CopyInto(dst *IntSet[E]) {
+	if dst == s {
+		return
+	}
 
-		var xi any = x
-		if xs, ok := xi.(fmt.Stringer); ok {
-			fmt.Fprint(&b, xs.String())
-		} else {
-			fmt.Fprintf(&b, "%d", int(x))
+	dst.words = resize(dst.words, len(s.words))
+	copy(dst.words, s.words)
+}
+
+// Map returns a new set holding f(x) for each element x of the set s,
+// leaving s unchanged. Values that f maps to the same result appear
+// once. The result is preallocated to the size of s, on the assumption
+// that f maps elements to values of about the same magnitude.
+// Map panics if f returns a negative value.
+func (s *IntSet[E]) Map(f func(E) E) *IntSet[E] {
+	m := &IntSet[E]{words: make([]uint, 0, len(s.liveWords()))}
+	s.forEach(func(x E) {
+		m.Add(f(x))
+	})
+
+	return m
+}
+
+// Filter returns a new set holding the elements x of the set s for
+// which pred(x) is true, leaving s unchanged.
+func (s *IntSet[E]) Filter(pred func(E) bool) *IntSet[E] {
+	words := s.liveWords()
+	f := &IntSet[E]{words: make([]uint, len(words))}
+	for i, w := range words {
+		for ; w != 0; w &= w - 1 {
+			tz := ntz(w)
+			if pred(E(wordSize*i + tz)) {
+				f.words[i] |= 1 << uint(tz)
+			}
+		}
+	}
+
+	f.trim()
+	return f
+}
+
+// Partition returns two new sets holding the elements x of the set s
+// for which pred(x) is true and false respectively, leaving s unchanged.
+func (s *IntSet[E]) Partition(pred func(E) bool) (in, out *IntSet[E]) {
+	words := s.liveWords()
+	in = &IntSet[E]{words: make([]uint, len(words))}
+	out = &IntSet[E]{words: make([]uint, len(words))}
+	for i, w := range words {
+		for ; w != 0; w &= w - 1 {
+			tz := ntz(w)
+			if pred(E(wordSize*i + tz)) {
+				in.words[i] |= 1 << uint(tz)
+			} else {
+				out.words[i] |= 1 << uint(tz)
+			}
+		}
+	}
+
+	in.trim()
+	out.trim()
+	return in, out
+}
+
+// WithRange returns a new set holding the union of s and the values
+// in [lo, hi), leaving s unchanged.
+func (s *IntSet[E]) WithRange(lo, hi E) *IntSet[E] {
+	sc := s.Copy()
+	sc.AddRange(lo, hi)
+
+	return sc
+}
+
+// ShiftRight subtracts k from each element of the set s, dropping the
+// elements less than k. It panics if k is negative.
+func (s *IntSet[E]) ShiftRight(k E) {
+	if k < 0 {
+		panic(fmt.Sprintf("intset: negative shift %d", int(k)))
+	}
+
+	w, bit := wordBit(toInt(k))
+	n := len(s.words) - w
+	if n < 0 {
+		n = 0
+	}
+
+	for i := 0; i < n; i++ {
+		word := s.words[i+w] >> bit
+		if bit != 0 && i+w+1 < len(s.words) {
+			word |= s.words[i+w+1] << (wordSize - bit)
+		}
+
+		s.words[i] = word
+	}
+
+	for i := n; i < len(s.words); i++ {
+		s.words[i] = 0
+	}
+	s.words = s.words[:n]
+	s.trim()
+}
+
+// Swap exchanges the contents of the sets s and t in O(1).
+func (s *IntSet[E]) Swap(t *IntSet[E]) {
+	s.words, t.words = t.words, s.words
+}
+
+// DebugWords returns a copy of the words backing the set s, including
+// any trailing zero words. It is intended for tests and debugging that
+// need to inspect the internal representation.
+func (s *IntSet[E]) DebugWords() []uint {
+	words := make([]uint, len(s.words))
+	copy(words, s.words)
+
+	return words
+}
+
+// ToBools returns a slice of length n whose element i is true if i is
+// in the set s. Elements of s of n or more are ignored. It panics if n
+// is negative.
+func (s *IntSet[E]) ToBools(n int) []bool {
+	if n < 0 {
+		panic(fmt.Sprintf("intset: negative length %d", n))
+	}
+
+	mask := make([]bool, n)
+	for i, w := range s.words {
+		base := wordSize * i
+		if base >= n {
+			break
 		}
+
+		for ; w != 0; w &= w - 1 {
+			x := base + ntz(w)
+			if x >= n {
+				break
+			}
+
+			mask[x] = true
+		}
+	}
+
+	return mask
+}
+
+// Words returns a copy of the words backing the set s, without
+// trailing zero words. Bit i of word j is set if s contains the value
+// j*bits.UintSize + i.
+func (s *IntSet[E]) Words() []uint {
+	live := s.liveWords()
+	if len(live) == 0 {
+		return nil
+	}
+
+	words := make([]uint, len(live))
+	copy(words, live)
+
+	return words
+}
+
+// Bit reports whether bit i of the representation of the set s, as
+// returned by Words, is set. It panics if i is negative.
+func (s *IntSet[E]) Bit(i int) bool {
+	if i < 0 {
+		negativeValue(i)
+	}
+
+	w, mask := wordMask(i)
+	return w < len(s.words) && s.words[w]&mask != 0
+}
+
+// ToBits returns the elements of the set s below 64 as the set bits of
+// an integer, and reports whether s has no element of 64 or more.
+func (s *IntSet[E]) ToBits() (x uint64, ok bool) {
+	ok = true
+	for i, w := range s.words {
+		if w == 0 {
+			continue
+		}
+
+		if wordSize*i >= 64 {
+			ok = false
+			break
+		}
+
+		x |= uint64(w) << uint(wordSize*i)
+	}
+
+	return x, ok
+}
+
+// BigInt returns a new big.Int with bit x set for each element x of
+// the set s.
+func (s *IntSet[E]) BigInt() *big.Int {
+	live := s.liveWords()
+	words := make([]big.Word, len(live))
+	for i, w := range live {
+		words[i] = big.Word(w)
+	}
+
+	return new(big.Int).SetBits(words)
+}
+
+// String returns a human-readable description of the set s.
+func (s *IntSet[E]) String() string {
+	return s.Format("{", " ", "}")
+}
+
+// AppendString appends the String representation of the set s to dst
+// and returns the extended slice.
+func (s *IntSet[E]) AppendString(dst []byte) []byte {
+	return s.appendFormat(dst, "{", " ", "}")
+}
+
+// Format returns the elements of the set s in order, formatted as by
+// String but separated by sep and enclosed in open and close.
+// For example, Format("[", ", ", "]") returns "[1, 9, 144]".
+func (s *IntSet[E]) Format(open, sep, close string) string {
+	return string(s.appendFormat(nil, open, sep, close))
+}
+
+// appendFormat appends the representation of the set s described by
+// Format to dst.
+func (s *IntSet[E]) appendFormat(dst []byte, open, sep, close string) []byte {
+	dst = append(dst, open...)
+	first := true
+	s.forEach(func(x E) {
+		if !first {
+			dst = append(dst, sep...)
+		}
+		first = false
+
+		dst = appendLabel(dst, x)
 	})
-	b.WriteByte('}')
 
-	return b.String()
+	return append(dst, close...)
+}
+
+// EachLabel calls f for each element x of the set s in order, together
+// with the label of x: its String method if E implements fmt.Stringer,
+// or its decimal form otherwise.
+func (s *IntSet[E]) EachLabel(f func(x E, label string)) {
+	s.forEach(func(x E) {
+		f(x, label(x))
+	})
+}
+
+// label returns the representation of x used by String.
+func label[E Integer](x E) string {
+	var xi any = x
+	if xs, ok := xi.(fmt.Stringer); ok {
+		return xs.String()
+	}
+
+	return strconv.Itoa(int(x))
+}
+
+// appendLabel appends the label of x to dst.
+func appendLabel[E Integer](dst []byte, x E) []byte {
+	var xi any = x
+	if xs, ok := xi.(fmt.Stringer); ok {
+		return append(dst, xs.String()...)
+	}
+
+	return strconv.AppendInt(dst, int64(x), 10)
 }
 
 // forEach applies function f to each element of the set s in order.
@@ -207,13 +1044,95 @@ func (s *IntSet[E]) String() string {
 // natural control flow with continue/break/return.
 func (s *IntSet[E]) forEach(f func(E)) {
 	for i, w := range s.words {
-		if w == 0 {
-			continue
+		for ; w != 0; w &= w - 1 {
+			f(E(wordSize*i + ntz(w)))
 		}
+	}
+}
 
-		for j := 0; j < wordSize; j++ {
-			if w&(1<<uint(j)) != 0 {
-				f(E(wordSize*i + j))
+// All returns an iterator over the elements of the set s in order.
+//
+// The set must not be mutated during iteration, as with forEach.
+func (s *IntSet[E]) All() iter.Seq[E] {
+	return func(yield func(E) bool) {
+		for i, w := range s.words {
+			for ; w != 0; w &= w - 1 {
+				if !yield(E(wordSize*i + ntz(w))) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// Backward returns an iterator over the elements of the set s in
+// descending order.
+//
+// The set must not be mutated during iteration, as with forEach.
+func (s *IntSet[E]) Backward() iter.Seq[E] {
+	return func(yield func(E) bool) {
+		for i := len(s.words) - 1; i > -1; i-- {
+			for w := s.words[i]; w != 0; {
+				j := wordSize - nlz(w) - 1
+				if !yield(E(wordSize*i + j)) {
+					return
+				}
+
+				w &^= 1 << uint(j)
+			}
+		}
+	}
+}
+
+// ForEachCoord calls f for each element x of the set s in order,
+// together with the index of the word holding x and the index of
+// its bit within that word. It stops early if f returns false.
+//
+// f must not mutate s.
+func (s *IntSet[E]) ForEachCoord(f func(x E, word int, bit uint) bool) {
+	for i, w := range s.words {
+		for ; w != 0; w &= w - 1 {
+			x := wordSize*i + ntz(w)
+			word, bit := wordBit(x)
+			if !f(E(x), word, bit) {
+				return
+			}
+		}
+	}
+}
+
+// ForEachRange calls f for each element of the set s in [lo, hi), in
+// order, and stops early if f returns false. It does nothing if
+// lo >= hi, and panics if lo is negative.
+//
+// f must not mutate s.
+func (s *IntSet[E]) ForEachRange(lo, hi E, f func(E) bool) {
+	if lo >= hi {
+		return
+	}
+
+	if lo < 0 {
+		negativeValue(lo)
+	}
+
+	lw, lbit := wordBit(toInt(lo))
+	hw, hbit := wordBit(toInt(hi) - 1)
+	if hw >= len(s.words) {
+		hw, hbit = len(s.words)-1, wordSize-1
+	}
+
+	for i := lw; i <= hw; i++ {
+		w := s.words[i]
+		if i == lw {
+			w &= bitRange(lbit, wordSize-1)
+		}
+		if i == hw {
+			w &= bitRange(0, hbit)
+		}
+
+		for ; w != 0; w &= w - 1 {
+			if !f(E(wordSize*i + ntz(w))) {
+				return
 			}
 		}
 	}
@@ -228,56 +1147,177 @@ func (s *IntSet[E]) forEach(f func(E)) {
 //	     {4,5}.BitString() = "110000"
 //	{0,4,5}.BitString() = "110001"
 func (s *IntSet[E]) BitString() string {
-	if s.IsEmpty() {
-		return "0"
+	return string(s.AppendBitString(nil))
+}
+
+// AppendBitString appends the BitString representation of the set s
+// to dst and returns the extended slice.
+func (s *IntSet[E]) AppendBitString(dst []byte) []byte {
+	words := s.liveWords()
+	if len(words) == 0 {
+		return append(dst, '0')
 	}
 
-	n := int(s.Max())
-	n++ // zero bit
-	radix := n
+	top := len(words) - 1
+	dst = slices.Grow(dst, top*wordSize+wordSize-nlz(words[top]))
 
-	b := make([]byte, n)
-	for i := range b {
-		b[i] = '0'
+	// The top word is written without its leading zeros, and every
+	// other word in full, a byte at a time from its high byte down.
+	w := words[top]
+	for bit := wordSize - 1 - nlz(w); bit >= 0; bit-- {
+		dst = append(dst, '0'+byte(w>>uint(bit)&1))
 	}
 
-	s.forEach(func(x E) {
-		b[radix-int(x)-1] = '1'
-	})
+	for i := top - 1; i >= 0; i-- {
+		w := words[i]
+		for j := wordSize - 8; j >= 0; j -= 8 {
+			dst = append(dst, bitStrings[byte(w>>uint(j))][:]...)
+		}
+	}
 
-	return *(*string)(unsafe.Pointer(&b))
+	return dst
 }
 
+// bitStrings[x] is the 8-digit binary representation of x.
+var bitStrings = func() (t [256][8]byte) {
+	for x := range t {
+		for j := range t[x] {
+			t[x][j] = '0' + byte(x>>uint(7-j)&1)
+		}
+	}
+
+	return t
+}()
+
 // Equals reports whether the sets s and t have the same elements.
 func (s *IntSet[E]) Equals(t *IntSet[E]) bool {
 	if s == t {
 		return true
 	}
 
-	if s.Len() != t.Len() {
-		return false
+	long, short := s.words, t.words
+	if len(long) < len(short) {
+		long, short = short, long
 	}
 
-	for i, w := range s.words {
-		if w == 0 {
-			continue
+	for i, w := range short {
+		if w != long[i] {
+			return false
 		}
+	}
 
-		if i >= len(t.words) {
+	for _, w := range long[len(short):] {
+		if w != 0 {
 			return false
 		}
+	}
 
-		if w != t.words[i] {
+	return true
+}
+
+// EqualElems reports whether the elements of the set s are exactly the
+// distinct values in xs, in any order. Negative values in xs are never
+// elements of s.
+func (s *IntSet[E]) EqualElems(xs []E) bool {
+	var seen IntSet[E]
+	for _, x := range xs {
+		if x < 0 || !s.Has(x) {
 			return false
 		}
+
+		seen.Add(x)
 	}
 
-	return true
+	return seen.Len() == s.Len()
 }
 
-// LowerBound returns the smallest element >= x, or MaxInt if there is no such element.
+// Hash returns a hash of the elements of the set s, computed with
+// 64-bit FNV-1a over the little-endian bytes of its words. Trailing
+// zero words are skipped, so that sets for which Equals reports true
+// have the same hash. The hash depends on the word size of the platform.
+func (s *IntSet[E]) Hash() uint64 {
+	const (
+		offset64 = 14695981039346656037
+		prime64  = 1099511628211
+	)
+
+	h := uint64(offset64)
+	for _, w := range s.liveWords() {
+		for i := 0; i < wordSize; i += 8 {
+			h ^= uint64(byte(w >> i))
+			h *= prime64
+		}
+	}
+
+	return h
+}
+
+// Compare compares the sets s and t as ascending sequences of
+// elements, lexicographically, so that a set that is a proper prefix of
+// the other is the smaller. The result is -1 if s < t, 0 if s == t and
+// +1 if s > t.
+func (s *IntSet[E]) Compare(t *IntSet[E]) int {
+	for i := 0; i < max(len(s.words), len(t.words)); i++ {
+		var sword, tword uint
+		if i < len(s.words) {
+			sword = s.words[i]
+		}
+		if i < len(t.words) {
+			tword = t.words[i]
+		}
+
+		if sword == tword {
+			continue
+		}
+
+		// The sequences first differ at x, which only one set holds.
+		// The other set is smaller only if it ends before x.
+		x := wordSize*i + ntz(sword^tword)
+		if s.Has(E(x)) {
+			if t.hasAbove(x) {
+				return -1
+			}
+			return +1
+		}
+
+		if s.hasAbove(x) {
+			return +1
+		}
+		return -1
+	}
+
+	return 0
+}
+
+// hasAbove reports whether the set s has an element greater than x.
+func (s *IntSet[E]) hasAbove(x int) bool {
+	w, bit := wordBit(x)
+	if w >= len(s.words) {
+		return false
+	}
+
+	if bit < wordSize-1 && s.words[w]>>(bit+1) != 0 {
+		return true
+	}
+
+	for _, word := range s.words[w+1:] {
+		if word != 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
+// LowerBound returns the smallest element >= x, or the largest value of E
+// (MaxInt for int) if there is no such element.
+// It panics if x is negative.
 func (s *IntSet[E]) LowerBound(x E) E {
-	w, bit := wordBit(int(x))
+	if x < 0 {
+		negativeValue(x)
+	}
+
+	w, bit := wordBit(toInt(x))
 
 	for i, word := range s.words {
 		if i < w {
@@ -304,10 +1344,135 @@ func (s *IntSet[E]) LowerBound(x E) E {
 		}
 	}
 
-	return MaxInt
+	return maxOf[E]()
+}
+
+// UpperBound returns the smallest element > x, or the largest value of E
+// (MaxInt for int) if there is no such element.
+func (s *IntSet[E]) UpperBound(x E) E {
+	if noneAbove(x) {
+		return maxOf[E]()
+	}
+
+	if x < 0 {
+		return s.LowerBound(0)
+	}
+
+	return s.LowerBound(x + 1)
+}
+
+// Next returns the smallest element > x and reports whether there is
+// such an element.
+func (s *IntSet[E]) Next(x E) (E, bool) {
+	if noneAbove(x) {
+		return 0, false
+	}
+
+	lo := 0
+	if x >= 0 {
+		lo = toInt(x) + 1
+	}
+
+	w, bit := wordBit(lo)
+	if w >= len(s.words) {
+		return 0, false
+	}
+
+	if word := s.words[w] >> bit; word != 0 {
+		return E(lo + ntz(word)), true
+	}
+
+	for i := w + 1; i < len(s.words); i++ {
+		if word := s.words[i]; word != 0 {
+			return E(wordSize*i + ntz(word)), true
+		}
+	}
+
+	return 0, false
+}
+
+// Prev returns the largest element < x and reports whether there is
+// such an element.
+func (s *IntSet[E]) Prev(x E) (E, bool) {
+	if x <= 0 || len(s.words) == 0 {
+		return 0, false
+	}
+	if uint64(x) > MaxInt {
+		// Every element is less than x.
+		return s.MaxOK()
+	}
+
+	w, bit := wordBit(toInt(x) - 1)
+	if w >= len(s.words) {
+		w, bit = len(s.words)-1, wordSize-1
+	}
+
+	if word := s.words[w] & bitRange(0, bit); word != 0 {
+		return E(wordSize*(w+1) - nlz(word) - 1), true
+	}
+
+	for i := w - 1; i > -1; i-- {
+		if word := s.words[i]; word != 0 {
+			return E(wordSize*(i+1) - nlz(word) - 1), true
+		}
+	}
+
+	return 0, false
+}
+
+// Rank returns the number of elements of the set s that are <= x.
+func (s *IntSet[E]) Rank(x E) int {
+	if x < 0 {
+		return 0
+	}
+	if noneAbove(x) {
+		return s.Len()
+	}
+
+	w, bit := wordBit(toInt(x))
+	if w >= len(s.words) {
+		return s.Len()
+	}
+
+	n := 0
+	for _, word := range s.words[:w] {
+		n += popcount(word)
+	}
+
+	return n + popcount(s.words[w]&bitRange(0, bit))
 }
 
-// Max returns the maximum element of the set s, or MinInt if s is empty.
+// Select returns the kth smallest element of the set s, counting from
+// zero, and reports whether there is such an element.
+func (s *IntSet[E]) Select(k int) (E, bool) {
+	if k < 0 {
+		return 0, false
+	}
+
+	for i, w := range s.words {
+		n := popcount(w)
+		if k >= n {
+			k -= n
+			continue
+		}
+
+		// Clear the k lowest set bits; the kth is then the lowest.
+		for ; k > 0; k-- {
+			w &= w - 1
+		}
+
+		return E(wordSize*i + ntz(w)), true
+	}
+
+	return 0, false
+}
+
+// Max returns the maximum element of the set s, or the smallest value of E
+// (MinInt for int) if s is empty.
+//
+// For unsigned E the smallest value is 0, which is also the maximum of
+// the set {0}; such callers must use MaxOK or IsEmpty to tell the two
+// apart.
 func (s *IntSet[E]) Max() E {
 	for i := len(s.words) - 1; i > -1; i-- {
 		w := s.words[i]
@@ -318,20 +1483,123 @@ func (s *IntSet[E]) Max() E {
 		return E(wordSize*(i+1) - nlz(w) - 1)
 	}
 
-	return MinInt
+	return minOf[E]()
+}
+
+// Min returns the minimum element of the set s, or the largest value of E
+// (MaxInt for int) if s is empty.
+//
+// For element types narrower than int the largest value may itself be
+// an element, such as 255 for uint8; such callers must use MinOK or
+// IsEmpty to tell an empty set apart.
+func (s *IntSet[E]) Min() E {
+	for i, w := range s.words {
+		if w == 0 {
+			continue
+		}
+
+		return E(wordSize*i + ntz(w))
+	}
+
+	return maxOf[E]()
+}
+
+// MinIntervals returns the maximal runs of consecutive elements of the
+// set s in order, each as an inclusive interval [lo, hi].
+//
+// The runs are disjoint and no two are adjacent, so no smaller number
+// of intervals covers exactly the elements of s.
+//
+// The intervals are inclusive, not half-open, so that a run ending at
+// the largest value of E, such as 255 for uint8, is representable.
+func (s *IntSet[E]) MinIntervals() [][2]E {
+	var runs [][2]E
+	s.forEachRun(func(lo, hi int) {
+		runs = append(runs, [2]E{E(lo), E(hi - 1)})
+	})
+
+	return runs
+}
+
+// MinIntervalCount returns the number of intervals MinIntervals
+// would return.
+func (s *IntSet[E]) MinIntervalCount() int {
+	n := 0
+	s.forEachRun(func(_, _ int) {
+		n++
+	})
+
+	return n
+}
+
+// RunLengthHistogram returns a map from each length of a maximal run of
+// consecutive elements of the set s to the number of runs of that length.
+func (s *IntSet[E]) RunLengthHistogram() map[int]int {
+	h := make(map[int]int)
+	s.forEachRun(func(lo, hi int) {
+		h[hi-lo]++
+	})
+
+	return h
+}
+
+// forEachRun applies function f to each maximal run [lo, hi) of
+// consecutive elements of the set s in order.
+func (s *IntSet[E]) forEachRun(f func(lo, hi int)) {
+	start := -1
+	for i, w := range s.words {
+		base := wordSize * i
+		for j := 0; j < wordSize; {
+			if start < 0 {
+				// Skip to the next set bit.
+				rest := w >> uint(j)
+				if rest == 0 {
+					break
+				}
+
+				j += ntz(rest)
+				start = base + j
+			} else {
+				// Skip to the next clear bit.
+				rest := ^w >> uint(j)
+				if rest == 0 {
+					break
+				}
+
+				j += ntz(rest)
+				f(start, base+j)
+				start = -1
+			}
+		}
+	}
+
+	if start >= 0 {
+		f(start, wordSize*len(s.words))
+	}
+}
+
+// MaxOK returns the maximum element of the set s and reports whether
+// s is non-empty. Unlike Max, it does not rely on a sentinel value.
+func (s *IntSet[E]) MaxOK() (E, bool) {
+	for i := len(s.words) - 1; i > -1; i-- {
+		if w := s.words[i]; w != 0 {
+			return E(wordSize*(i+1) - nlz(w) - 1), true
+		}
+	}
+
+	return 0, false
 }
 
-// Min returns the minimum element of the set s, or MaxInt if s is empty.
-func (s *IntSet[E]) Min() E {
+// MinOK returns the minimum element of the set s and reports whether
+// s is non-empty. Unlike Min, it does not rely on a sentinel value.
+func (s *IntSet[E]) MinOK() (E, bool) {
 	for i, w := range s.words {
-		if w == 0 {
-			continue
+		if w != 0 {
+			return E(wordSize*i + ntz(w)), true
 		}
-
-		return E(wordSize*i + ntz(w))
 	}
 
-	return MaxInt
+	return 0, false
 }
 
 // UnionWith sets s to the union s ∪ t.
@@ -345,6 +1613,30 @@ func (s *IntSet[E]) UnionWith(t *IntSet[E]) {
 	}
 }
 
+// UnionWithAll sets s to the union of s and each of ts. Unlike repeated
+// calls to UnionWith, it grows s at most once.
+func (s *IntSet[E]) UnionWithAll(ts ...*IntSet[E]) {
+	n := len(s.words)
+	for _, t := range ts {
+		n = max(n, len(t.liveWords()))
+	}
+	s.extend(n)
+
+	for _, t := range ts {
+		for i, tword := range t.liveWords() {
+			s.words[i] |= tword
+		}
+	}
+}
+
+// Union returns a new set holding the union s ∪ t.
+func (s *IntSet[E]) Union(t *IntSet[E]) *IntSet[E] {
+	u := &IntSet[E]{}
+	s.UnionInto(u, t)
+
+	return u
+}
+
 // IntersectWith sets s to the intersection s ∩ t.
 func (s *IntSet[E]) IntersectWith(t *IntSet[E]) {
 	for i := range s.words {
@@ -354,6 +1646,43 @@ func (s *IntSet[E]) IntersectWith(t *IntSet[E]) {
 			s.words[i] = 0
 		}
 	}
+
+	s.trim()
+}
+
+// IntersectWithAll sets s to the intersection of s and each of ts.
+// It stops as soon as the intersection becomes empty.
+func (s *IntSet[E]) IntersectWithAll(ts ...*IntSet[E]) {
+	for _, t := range ts {
+		if len(t.words) < len(s.words) {
+			clear(s.words[len(t.words):])
+			s.words = s.words[:len(t.words)]
+		}
+
+		empty := true
+		for i := range s.words {
+			s.words[i] &= t.words[i]
+			if s.words[i] != 0 {
+				empty = false
+			}
+		}
+
+		if empty {
+			s.words = s.words[:0]
+			return
+		}
+	}
+
+	s.trim()
+}
+
+// Intersection returns a new set holding the intersection s ∩ t.
+func (s *IntSet[E]) Intersection(t *IntSet[E]) *IntSet[E] {
+	u := &IntSet[E]{}
+	s.IntersectInto(u, t)
+	u.trim()
+
+	return u
 }
 
 // Intersects reports whether s ∩ x ≠ ∅.
@@ -375,6 +1704,65 @@ func (s *IntSet[E]) Intersects(t *IntSet[E]) bool {
 	return false
 }
 
+// DisjointFrom reports whether s ∩ t = ∅.
+func (s *IntSet[E]) DisjointFrom(t *IntSet[E]) bool {
+	n := min(len(s.words), len(t.words))
+	for i := 0; i < n; i++ {
+		if s.words[i]&t.words[i] != 0 {
+			return false
+		}
+	}
+
+	return true
+}
+
+// IntersectionCardinality returns |s ∩ t| without building the intersection.
+func (s *IntSet[E]) IntersectionCardinality(t *IntSet[E]) int {
+	n := 0
+	for i, tword := range t.words {
+		if i >= len(s.words) {
+			break
+		}
+
+		n += popcount(s.words[i] & tword)
+	}
+
+	return n
+}
+
+// UnionCardinality returns |s ∪ t| without building the union.
+func (s *IntSet[E]) UnionCardinality(t *IntSet[E]) int {
+	long, short := s.words, t.words
+	if len(long) < len(short) {
+		long, short = short, long
+	}
+
+	n := 0
+	for i, word := range long {
+		if i < len(short) {
+			word |= short[i]
+		}
+
+		n += popcount(word)
+	}
+
+	return n
+}
+
+// DifferenceCardinality returns |s ∖ t| without building the difference.
+func (s *IntSet[E]) DifferenceCardinality(t *IntSet[E]) int {
+	n := 0
+	for i, word := range s.words {
+		if i < len(t.words) {
+			word &^= t.words[i]
+		}
+
+		n += popcount(word)
+	}
+
+	return n
+}
+
 // DifferenceWith sets s to the difference s ∖ t.
 func (s *IntSet[E]) DifferenceWith(t *IntSet[E]) {
 	if s == t {
@@ -387,10 +1775,26 @@ func (s *IntSet[E]) DifferenceWith(t *IntSet[E]) {
 			s.words[i] &^= tword
 		}
 	}
+
+	s.trim()
+}
+
+// Difference returns a new set holding the difference s ∖ t.
+func (s *IntSet[E]) Difference(t *IntSet[E]) *IntSet[E] {
+	u := &IntSet[E]{}
+	s.DifferenceInto(u, t)
+	u.trim()
+
+	return u
 }
 
 // SymmetricDifference sets s to the symmetric difference s ∆ t.
 func (s *IntSet[E]) SymmetricDifference(t *IntSet[E]) {
+	if s == t {
+		s.Clear()
+		return
+	}
+
 	for i, tword := range t.words {
 		if i < len(s.words) {
 			s.words[i] ^= tword
@@ -398,6 +1802,153 @@ func (s *IntSet[E]) SymmetricDifference(t *IntSet[E]) {
 			s.words = append(s.words, tword)
 		}
 	}
+
+	s.trim()
+}
+
+// Jaccard returns the Jaccard similarity |s ∩ t| / |s ∪ t| of the sets
+// s and t. It is 1 if both sets are empty.
+func (s *IntSet[E]) Jaccard(t *IntSet[E]) float64 {
+	u := s.UnionCardinality(t)
+	if u == 0 {
+		return 1
+	}
+
+	return float64(s.IntersectionCardinality(t)) / float64(u)
+}
+
+// UnionInto sets dst to the union s ∪ t, reusing the storage of dst
+// when it is large enough. dst may be s or t.
+func (s *IntSet[E]) UnionInto(dst, t *IntSet[E]) {
+	long, short := s.words, t.words
+	if len(long) < len(short) {
+		long, short = short, long
+	}
+
+	words := resize(dst.words, len(long))
+	for i, word := range long {
+		if i < len(short) {
+			word |= short[i]
+		}
+
+		words[i] = word
+	}
+
+	dst.words = words
+}
+
+// IntersectInto sets dst to the intersection s ∩ t, reusing the storage
+// of dst when it is large enough. dst may be s or t.
+func (s *IntSet[E]) IntersectInto(dst, t *IntSet[E]) {
+	n := len(s.words)
+	if len(t.words) < n {
+		n = len(t.words)
+	}
+
+	words := resize(dst.words, n)
+	for i := range words {
+		words[i] = s.words[i] & t.words[i]
+	}
+
+	dst.words = words
+}
+
+// DifferenceInto sets dst to the difference s ∖ t, reusing the storage
+// of dst when it is large enough. dst may be s or t.
+func (s *IntSet[E]) DifferenceInto(dst, t *IntSet[E]) {
+	words := resize(dst.words, len(s.words))
+	for i, word := range s.words {
+		if i < len(t.words) {
+			word &^= t.words[i]
+		}
+
+		words[i] = word
+	}
+
+	dst.words = words
+}
+
+// SymmetricDiff returns a new set holding the symmetric difference s ∆ t.
+func (s *IntSet[E]) SymmetricDiff(t *IntSet[E]) *IntSet[E] {
+	u, _ := s.ExactlyOne(t)
+	return u
+}
+
+// ExactlyOne returns a new set holding the elements that are in
+// exactly one of s and t, that is, the symmetric difference s ∆ t,
+// together with its number of elements.
+func (s *IntSet[E]) ExactlyOne(t *IntSet[E]) (*IntSet[E], int) {
+	long, short := s.words, t.words
+	if len(long) < len(short) {
+		long, short = short, long
+	}
+
+	u := &IntSet[E]{words: make([]uint, len(long))}
+	n := 0
+	for i, word := range long {
+		if i < len(short) {
+			word ^= short[i]
+		}
+
+		u.words[i] = word
+		n += popcount(word)
+	}
+	u.trim()
+
+	return u, n
+}
+
+// Complement returns a new set holding the values in [0, n) that are
+// not in the set s. Elements of s that are >= n do not affect the result.
+func (s *IntSet[E]) Complement(n E) *IntSet[E] {
+	c := &IntSet[E]{}
+	if n <= 0 {
+		return c
+	}
+
+	nw, bit := wordBit(toInt(n))
+	if bit != 0 {
+		nw++
+	}
+
+	c.words = make([]uint, nw)
+	for i := range c.words {
+		var word uint
+		if i < len(s.words) {
+			word = s.words[i]
+		}
+
+		c.words[i] = ^word
+	}
+
+	if bit != 0 {
+		c.words[nw-1] &= 1<<bit - 1
+	}
+	c.trim()
+
+	return c
+}
+
+// SymmetricDifferencePerWord returns, for each word index i of either
+// set, the number of elements in word i of the symmetric difference s ∆ t.
+// The result runs up to the higher of the words holding the largest
+// element of s and of t, so it depends only on the contents of the sets.
+func (s *IntSet[E]) SymmetricDifferencePerWord(t *IntSet[E]) []int {
+	long, short := s.liveWords(), t.liveWords()
+	if len(long) < len(short) {
+		long, short = short, long
+	}
+
+	counts := make([]int, len(long))
+	for i, word := range long {
+		if i < len(short) {
+			word ^= short[i]
+		}
+
+		counts[i] = popcount(word)
+	}
+
+	return counts
 }
 
 // SubsetOf reports whether s ∖ t = ∅.
@@ -419,6 +1970,185 @@ func (s *IntSet[E]) SubsetOf(t *IntSet[E]) bool {
 	return true
 }
 
+// SupersetOf reports whether t ∖ s = ∅.
+func (s *IntSet[E]) SupersetOf(t *IntSet[E]) bool {
+	for i, tword := range t.words {
+		if tword == 0 {
+			continue
+		}
+
+		if i >= len(s.words) {
+			return false
+		}
+
+		if tword&^s.words[i] != 0 {
+			return false
+		}
+	}
+
+	return true
+}
+
+// ProperSubsetOf reports whether s ⊂ t, that is, s ∖ t = ∅ and s ≠ t.
+func (s *IntSet[E]) ProperSubsetOf(t *IntSet[E]) bool {
+	proper := false
+	for i, word := range s.words {
+		var tword uint
+		if i < len(t.words) {
+			tword = t.words[i]
+		}
+
+		if word&^tword != 0 {
+			return false
+		}
+
+		if word != tword {
+			proper = true
+		}
+	}
+
+	if proper || len(t.words) <= len(s.words) {
+		return proper
+	}
+
+	for _, tword := range t.words[len(s.words):] {
+		if tword != 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ProperSupersetOf reports whether s ⊃ t, that is, t ∖ s = ∅ and s ≠ t.
+func (s *IntSet[E]) ProperSupersetOf(t *IntSet[E]) bool {
+	return t.ProperSubsetOf(s)
+}
+
+// Cosine returns the cosine similarity |s ∩ t| / sqrt(|s| * |t|) of
+// the sets s and t, or 0 if either set is empty.
+func (s *IntSet[E]) Cosine(t *IntSet[E]) float64 {
+	n, m := s.Len(), t.Len()
+	if n == 0 || m == 0 {
+		return 0
+	}
+
+	return float64(s.IntersectionCardinality(t)) / math.Sqrt(float64(n)*float64(m))
+}
+
+// extend appends zero words to s.words until it has length at least n.
+func (s *IntSet[E]) extend(n int) {
+	if n > len(s.words) {
+		s.words = append(s.words, make([]uint, n-len(s.words))...)
+	}
+}
+
+// trim slices off the trailing zero words of s.words.
+func (s *IntSet[E]) trim() {
+	s.words = s.liveWords()
+}
+
+// liveWords returns s.words without its trailing zero words.
+func (s *IntSet[E]) liveWords() []uint {
+	n := len(s.words)
+	for n > 0 && s.words[n-1] == 0 {
+		n--
+	}
+
+	return s.words[:n]
+}
+
+// resize returns a slice of length n, reusing the storage of words if
+// its capacity suffices. The contents of the result are unspecified.
+func resize(words []uint, n int) []uint {
+	if n <= cap(words) {
+		return words[:n]
+	}
+
+	return make([]uint, n)
+}
+
+// bitRange returns the mask of bits lo through hi, inclusive, of a word.
+func bitRange(lo, hi uint) uint {
+	return ^uint(0) << lo & (^uint(0) >> (wordSize - 1 - hi))
+}
+
+// negativeValue panics reporting that x, an argument to a method
+// taking non-negative values, is negative.
+func negativeValue[E Integer](x E) {
+	panic(fmt.Sprintf("intset: negative value %d", x))
+}
+
+// maxOf returns the largest value of type E.
+func maxOf[E Integer]() E {
+	var zero E
+	if ^zero > 0 {
+		return ^zero // unsigned
+	}
+
+	return E(1)<<(unsafe.Sizeof(zero)*8-1) - 1
+}
+
+// minOf returns the smallest value of type E.
+func minOf[E Integer]() E {
+	var zero E
+	if ^zero > 0 {
+		return 0 // unsigned
+	}
+
+	return ^maxOf[E]()
+}
+
+// toInt returns x, a non-negative argument to a method, as an int.
+// It panics if x is negative or does not fit in an int; the latter is
+// possible only for 64-bit or unsigned element types.
+func toInt[E Integer](x E) int {
+	if uint64(x) > MaxInt {
+		outOfRange(x)
+	}
+
+	return int(x)
+}
+
+// noneAbove reports whether no element of a set can exceed x, because x
+// is the largest value of E or is at least MaxInt.
+func noneAbove[E Integer](x E) bool {
+	return x == maxOf[E]() || x >= 0 && uint64(x) >= MaxInt
+}
+
+// outOfRange panics reporting that x, an argument to a method, is
+// negative or does not fit in an int.
+func outOfRange[E Integer](x E) {
+	if x < 0 {
+		negativeValue(x)
+	}
+
+	panic(fmt.Sprintf("intset: value %d out of range", x))
+}
+
+// inRange reports whether the non-negative int x is a value of type E.
+func inRange[E Integer](x int) bool {
+	return int(E(x)) == x && E(x) >= 0
+}
+
+// fitsElems reports whether every element of the set s, whose words
+// may come from outside, is a value of type E.
+func (s *IntSet[E]) fitsElems() bool {
+	words := s.liveWords()
+	if len(words) == 0 {
+		return true
+	}
+
+	return inRange[E](wordSize*len(words) - nlz(words[len(words)-1]) - 1)
+}
+
+// mustFitElems panics if an element of the set s is not a value of type E.
+func (s *IntSet[E]) mustFitElems() {
+	if !s.fitsElems() {
+		panic(errElemRange.Error())
+	}
+}
+
 // popcount returns the number of set bits in w.
 func popcount(w uint) int {
 	return bits.OnesCount(w)
@@ -433,3 +2163,82 @@ func nlz(x uint) int {
 func ntz(x uint) int {
 	return bits.TrailingZeros(x)
 }
+
+// NotInAll returns a new set holding the values of [0, universe) that
+// are absent from at least one of sets, that is, the complement within
+// [0, universe) of the intersection of sets.
+//
+// With no sets the result is empty.
+func NotInAll[E Integer](universe E, sets ...*IntSet[E]) *IntSet[E] {
+	r := &IntSet[E]{}
+	if universe <= 0 || len(sets) == 0 {
+		return r
+	}
+
+	n, bit := wordBit(toInt(universe))
+	if bit != 0 {
+		n++
+	}
+
+	r.words = make([]uint, n)
+	for i := range r.words {
+		all := ^uint(0)
+		for _, s := range sets {
+			if i >= len(s.words) {
+				all = 0
+				break
+			}
+
+			all &= s.words[i]
+		}
+
+		r.words[i] = ^all
+	}
+
+	if bit != 0 {
+		r.words[n-1] &= 1<<bit - 1
+	}
+
+	r.trim()
+	return r
+}
+
+// UnionAll returns a new set holding the union of sets, leaving them
+// unchanged. The result is allocated once, at the size of the largest
+// operand. With no sets the result is empty.
+func UnionAll[E Integer](sets ...*IntSet[E]) *IntSet[E] {
+	n := 0
+	for _, s := range sets {
+		n = max(n, len(s.liveWords()))
+	}
+
+	u := &IntSet[E]{}
+	if n == 0 {
+		return u
+	}
+
+	u.words = make([]uint, n)
+	for _, s := range sets {
+		for i, w := range s.liveWords() {
+			u.words[i] |= w
+		}
+	}
+
+	return u
+}
+
+// Reduce folds f over the elements of the set s in ascending order,
+// starting from init, and returns the final result. For example, the
+// sum of the elements of s is
+//
+//	Reduce(s, 0, func(sum, x int) int { return sum + x })
+func Reduce[E Integer, T any](s *IntSet[E], init T, f func(acc T, x E) T) T {
+	acc := init
+	for i, w := range s.words {
+		for ; w != 0; w &= w - 1 {
+			acc = f(acc, E(wordSize*i+ntz(w)))
+		}
+	}
+
+	return acc
+}
@@ -415,3 +415,212 @@ func BenchmarkDifferenceWith(b *testing.B) {
 		},
 	})
 }
+
+func benchmarkBulkAdd(b *testing.B, grow bool) {
+	const n = 100000
+
+	for i := 0; i < b.N; i++ {
+		var s intset.IntSet[int]
+		if grow {
+			s.Grow(n)
+		}
+
+		for x := 0; x < n; x += 3 {
+			s.Add(x)
+		}
+	}
+}
+
+func BenchmarkBulkAdd(b *testing.B) {
+	benchmarkBulkAdd(b, false)
+}
+
+func BenchmarkBulkAddGrow(b *testing.B) {
+	benchmarkBulkAdd(b, true)
+}
+
+func benchmarkRefill(b *testing.B, empty func(s *intset.IntSet[int])) {
+	var s intset.IntSet[int]
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		for x := 0; x < 10000; x += 3 {
+			s.Add(x)
+		}
+		empty(&s)
+	}
+}
+
+func BenchmarkRefillClear(b *testing.B) {
+	benchmarkRefill(b, (*intset.IntSet[int]).Clear)
+}
+
+func BenchmarkRefillReset(b *testing.B) {
+	benchmarkRefill(b, (*intset.IntSet[int]).Reset)
+}
+
+func BenchmarkElemsSparse(b *testing.B) {
+	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+
+	// About one element per word.
+	var s intset.IntSet[int]
+	for i := 0; i < 10000; i++ {
+		s.Add(r.Intn(64 * 10000))
+	}
+
+	elems := make([]int, 0, s.Len())
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		s.AppendTo(elems[:0])
+	}
+}
+
+func BenchmarkEqualsLarge(b *testing.B) {
+	var s intset.IntSet[int]
+	for x := 0; x < 1000000; x += 3 {
+		s.Add(x)
+	}
+	t := s.Copy()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if !s.Equals(t) {
+			b.Fatal("Equals: got false")
+		}
+	}
+}
+
+func BenchmarkCopyFresh(b *testing.B) {
+	var s intset.IntSet[int]
+	for x := 0; x < 100000; x += 3 {
+		s.Add(x)
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		s.Copy()
+	}
+}
+
+func BenchmarkCopyIntoWarm(b *testing.B) {
+	var s intset.IntSet[int]
+	for x := 0; x < 100000; x += 3 {
+		s.Add(x)
+	}
+	dst := s.Copy()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		s.CopyInto(dst)
+	}
+}
+
+func BenchmarkBitStringDense(b *testing.B) {
+	var s intset.IntSet[int]
+	for x := 0; x < 100000; x += 2 {
+		s.Add(x)
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		s.BitString()
+	}
+}
+
+func benchmarkElemsDense(b *testing.B, elems func(s *intset.IntSet[int]) []int) {
+	var s intset.IntSet[int]
+	for x := 0; x < 1000000; x++ {
+		if x%7 != 0 {
+			s.Add(x)
+		}
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		elems(&s)
+	}
+}
+
+func BenchmarkElemsDense(b *testing.B) {
+	benchmarkElemsDense(b, (*intset.IntSet[int]).Elems)
+}
+
+// BenchmarkElemsDenseOnePass collects the elements in a single pass,
+// growing the result as needed, for comparison with Elems.
+func BenchmarkElemsDenseOnePass(b *testing.B) {
+	benchmarkElemsDense(b, func(s *intset.IntSet[int]) []int {
+		var elems []int
+		for x := range s.All() {
+			elems = append(elems, x)
+		}
+
+		return elems
+	})
+}
+
+func benchmarkAddUnsorted(b *testing.B, add func(s *intset.IntSet[int], xs []int)) {
+	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+	xs := make([]int, 100000)
+	for i := range xs {
+		xs[i] = r.Intn(1000000)
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var s intset.IntSet[int]
+		add(&s, xs)
+	}
+}
+
+func BenchmarkAddAllUnsorted(b *testing.B) {
+	benchmarkAddUnsorted(b, func(s *intset.IntSet[int], xs []int) {
+		s.AddAll(xs...)
+	})
+}
+
+func BenchmarkAddSliceUnsorted(b *testing.B) {
+	benchmarkAddUnsorted(b, (*intset.IntSet[int]).AddSlice)
+}
+
+func smallSets(n int) []*intset.IntSet[int] {
+	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+	sets := make([]*intset.IntSet[int], n)
+	for i := range sets {
+		sets[i] = new(intset.IntSet[int])
+		for j := 0; j < 10; j++ {
+			sets[i].Add(r.Intn(10000))
+		}
+	}
+
+	return sets
+}
+
+func BenchmarkUnionWithLoop(b *testing.B) {
+	sets := smallSets(100)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var s intset.IntSet[int]
+		for _, t := range sets {
+			s.UnionWith(t)
+		}
+	}
+}
+
+func BenchmarkUnionWithAll(b *testing.B) {
+	sets := smallSets(100)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var s intset.IntSet[int]
+		s.UnionWithAll(sets...)
+	}
+}
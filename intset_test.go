@@ -1,8 +1,12 @@
 package intset_test
 
 import (
+	"fmt"
+	"math"
+	"math/big"
 	"math/rand"
 	"reflect"
+	"slices"
 	"strings"
 	"testing"
 	"testing/quick"
@@ -10,6 +14,7 @@ import (
 	"github.com/weiwenchen2022/intset"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
 )
 
 type IntSet struct {
@@ -396,6 +401,69 @@ func TestTakeMin(t *testing.T) {
 	}
 }
 
+func TestPop(t *testing.T) {
+	t.Parallel()
+
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 100; i++ {
+		var s intset.IntSet[int]
+		s.AddAll(randValues(r)...)
+		want := s.Elems()
+
+		var got []int
+		for {
+			x, ok := s.Pop()
+			if !ok {
+				break
+			}
+			got = append(got, x)
+		}
+
+		if !cmp.Equal(want, got, cmpopts.EquateEmpty()) {
+			t.Fatal(cmp.Diff(want, got, cmpopts.EquateEmpty()))
+		}
+
+		if x, ok := s.Pop(); ok || x != 0 || !s.IsEmpty() {
+			t.Fatalf("Pop on empty set: got %d, %t", x, ok)
+		}
+	}
+}
+
+func TestPopMax(t *testing.T) {
+	t.Parallel()
+
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 100; i++ {
+		var s intset.IntSet[int]
+		s.AddAll(randValues(r)...)
+		want := s.Elems()
+		slices.Reverse(want)
+
+		var got []int
+		for n := s.Len(); ; n-- {
+			x, ok := s.PopMax()
+			if ok != (n > 0) {
+				t.Fatalf("PopMax with %d elements left: got %t", n, ok)
+			}
+			if !ok {
+				break
+			}
+			got = append(got, x)
+
+			if !s.IsEmpty() && len(s.DebugWords()) != s.Max()/(32<<(^uint(0)>>63))+1 {
+				t.Fatalf("PopMax left trailing zero words in %s", &s)
+			}
+		}
+
+		if !cmp.Equal(want, got, cmpopts.EquateEmpty()) {
+			t.Fatal(cmp.Diff(want, got, cmpopts.EquateEmpty()))
+		}
+		if n := len(s.DebugWords()); n != 0 {
+			t.Fatalf("PopMax drained set has %d words", n)
+		}
+	}
+}
+
 func TestMinAndMax(t *testing.T) {
 	t.Parallel()
 
@@ -468,6 +536,26 @@ func TestIntersects(t *testing.T) {
 	}
 }
 
+func TestDisjointFrom(t *testing.T) {
+	t.Parallel()
+
+	f := func(calls []intSetCall) []setResult {
+		return applyIntSetCalls(func(s, t *intset.IntSet[int]) (any, bool) {
+			return nil, !s.Intersects(t)
+		}, calls)
+	}
+
+	g := func(calls []intSetCall) []setResult {
+		return applyIntSetCalls(func(s, t *intset.IntSet[int]) (any, bool) {
+			return nil, s.DisjointFrom(t)
+		}, calls)
+	}
+
+	if err := quick.CheckEqual(f, g, nil); err != nil {
+		t.Error(err)
+	}
+}
+
 func TestSubsetOf(t *testing.T) {
 	t.Parallel()
 
@@ -489,6 +577,26 @@ func TestSubsetOf(t *testing.T) {
 	}
 }
 
+func TestSupersetOf(t *testing.T) {
+	t.Parallel()
+
+	f := func(calls []intSetCall) []setResult {
+		return applyIntSetCalls(func(s, t *intset.IntSet[int]) (any, bool) {
+			return nil, t.SubsetOf(s)
+		}, calls)
+	}
+
+	g := func(calls []intSetCall) []setResult {
+		return applyIntSetCalls(func(s, t *intset.IntSet[int]) (any, bool) {
+			return nil, s.SupersetOf(t)
+		}, calls)
+	}
+
+	if err := quick.CheckEqual(f, g, nil); err != nil {
+		t.Error(err)
+	}
+}
+
 func TestEquals(t *testing.T) {
 	t.Parallel()
 
@@ -509,6 +617,215 @@ func TestEquals(t *testing.T) {
 	}
 }
 
+func TestEqualElems(t *testing.T) {
+	t.Parallel()
+
+	var s intset.IntSet[int]
+	s.AddAll(1, 9, 144)
+
+	for _, tc := range []struct {
+		xs   []int
+		want bool
+	}{
+		{[]int{1, 9, 144}, true},
+		{[]int{144, 1, 9}, true},
+		{[]int{9, 144, 9, 1, 1}, true},
+		{[]int{1, 9}, false},
+		{[]int{1, 9, 9}, false},
+		{[]int{1, 9, 144, 2}, false},
+		{[]int{1, 9, 145}, false},
+		{[]int{-1, 1, 9, 144}, false},
+		{nil, false},
+	} {
+		if got := s.EqualElems(tc.xs); got != tc.want {
+			t.Errorf("%s.EqualElems(%v): got %t, want %t", &s, tc.xs, got, tc.want)
+		}
+	}
+
+	var empty intset.IntSet[int]
+	if !empty.EqualElems(nil) || empty.EqualElems([]int{0}) {
+		t.Error("EqualElems on empty set: wrong result")
+	}
+
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 100; i++ {
+		xs := randValues(r)
+		var s intset.IntSet[int]
+		s.AddAll(xs...)
+
+		r.Shuffle(len(xs), func(i, j int) { xs[i], xs[j] = xs[j], xs[i] })
+		if !s.EqualElems(xs) {
+			t.Fatalf("%s.EqualElems(%v): got false", &s, xs)
+		}
+	}
+}
+
+func TestHash(t *testing.T) {
+	t.Parallel()
+
+	f := func(calls []intSetCall) []setResult {
+		return applyIntSetCalls(func(s, t *intset.IntSet[int]) (any, bool) {
+			return nil, s.Equals(t)
+		}, calls)
+	}
+
+	g := func(calls []intSetCall) []setResult {
+		return applyIntSetCalls(func(s, t *intset.IntSet[int]) (any, bool) {
+			return nil, s.Hash() == t.Hash()
+		}, calls)
+	}
+
+	if err := quick.CheckEqual(f, g, nil); err != nil {
+		t.Error(err)
+	}
+
+	// Trailing zero words do not affect the hash.
+	var s, u intset.IntSet[int]
+	s.AddAll(1, 9, 144)
+	u.AddAll(1, 9, 144)
+	intset.SetWords(&u, append(u.DebugWords(), 0, 0))
+	if !s.Equals(&u) || s.Hash() != u.Hash() {
+		t.Errorf("Hash with trailing zero words: got %#x, want %#x", u.Hash(), s.Hash())
+	}
+
+	var empty intset.IntSet[int]
+	if empty.Hash() != intset.FromWords[int]([]uint{0}).Hash() {
+		t.Error("Hash of empty sets differ")
+	}
+}
+
+func TestUnionWithAll(t *testing.T) {
+	t.Parallel()
+
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 100; i++ {
+		var s intset.IntSet[int]
+		s.AddAll(randValues(r)...)
+
+		ts := make([]*intset.IntSet[int], r.Intn(5))
+		for j := range ts {
+			ts[j] = intset.FromSlice(randValues(r))
+		}
+
+		want := s.Copy()
+		for _, t := range ts {
+			want.UnionWith(t)
+		}
+
+		s.UnionWithAll(ts...)
+		if !s.Equals(want) {
+			t.Fatalf("UnionWithAll: got %s, want %s", &s, want)
+		}
+	}
+
+	var s intset.IntSet[int]
+	s.AddAll(1, 9)
+	s.UnionWithAll(&s, intset.FromSlice([]int{144}))
+	if want, got := "{1 9 144}", s.String(); !cmp.Equal(want, got) {
+		t.Error(cmp.Diff(want, got))
+	}
+}
+
+func TestUnionAll(t *testing.T) {
+	t.Parallel()
+
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 100; i++ {
+		sets := make([]*intset.IntSet[int], r.Intn(5))
+		for j := range sets {
+			sets[j] = intset.FromSlice(randValues(r))
+		}
+
+		want := new(intset.IntSet[int])
+		var before []string
+		for _, s := range sets {
+			want.UnionWith(s)
+			before = append(before, s.String())
+		}
+
+		got := intset.UnionAll(sets...)
+		if !got.Equals(want) {
+			t.Fatalf("UnionAll: got %s, want %s", got, want)
+		}
+
+		for j, s := range sets {
+			if s.String() != before[j] {
+				t.Fatalf("UnionAll changed operand %d from %s to %s", j, before[j], s)
+			}
+		}
+	}
+
+	if u := intset.UnionAll[int](); !u.IsEmpty() {
+		t.Errorf("UnionAll(): got %s", u)
+	}
+
+	s := intset.FromSlice([]int{1, 9, 144})
+	u := intset.UnionAll(s)
+	if !u.Equals(s) {
+		t.Errorf("UnionAll(%s): got %s", s, u)
+	}
+	u.Add(2)
+	if s.Has(2) {
+		t.Errorf("UnionAll(%s) shares storage with its operand", s)
+	}
+}
+
+func TestIntersectWithAll(t *testing.T) {
+	t.Parallel()
+
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 100; i++ {
+		var s intset.IntSet[int]
+		s.AddRange(0, r.Intn(1<<12))
+
+		ts := make([]*intset.IntSet[int], r.Intn(4))
+		for j := range ts {
+			ts[j] = new(intset.IntSet[int])
+			ts[j].AddRange(r.Intn(1<<11), r.Intn(1<<13))
+		}
+
+		want := s.Copy()
+		for _, t := range ts {
+			want.IntersectWith(t)
+		}
+
+		s.IntersectWithAll(ts...)
+		if !s.Equals(want) {
+			t.Fatalf("IntersectWithAll: got %s, want %s", &s, want)
+		}
+		if got, want := len(s.DebugWords()), len(want.DebugWords()); got != want {
+			t.Fatalf("IntersectWithAll: got %d words, want %d", got, want)
+		}
+	}
+
+	// The intersection is empty after the second set, and stays so
+	// whatever follows.
+	var s intset.IntSet[int]
+	s.AddAll(1, 9, 144)
+	s.IntersectWithAll(
+		intset.FromSlice([]int{1, 9}),
+		intset.FromSlice([]int{144}),
+		intset.FromSlice([]int{1, 9, 144}),
+	)
+	if !s.IsEmpty() || len(s.DebugWords()) != 0 {
+		t.Errorf("IntersectWithAll with empty result: got %s", &s)
+	}
+
+	s.AddAll(1, 9, 144)
+	s.IntersectWithAll(&s)
+	if want, got := "{1 9 144}", s.String(); !cmp.Equal(want, got) {
+		t.Error(cmp.Diff(want, got))
+	}
+
+	// Words dropped for being beyond a shorter set are zeroed.
+	words := []uint{1, 1, 1}
+	intset.SetWords(&s, words)
+	s.IntersectWithAll(intset.FromSlice([]int{0}))
+	if !cmp.Equal([]uint{1, 0, 0}, words) {
+		t.Errorf("IntersectWithAll left removed words %v", words)
+	}
+}
+
 func TestSymmetricDifference(t *testing.T) {
 	t.Parallel()
 
@@ -535,6 +852,40 @@ func TestSymmetricDifference(t *testing.T) {
 	}
 }
 
+func TestSymmetricDifferenceSelf(t *testing.T) {
+	t.Parallel()
+
+	var s intset.IntSet[int]
+	s.AddAll(1, 9, 144, 1<<12)
+
+	s.SymmetricDifference(&s)
+	if !s.IsEmpty() {
+		t.Errorf("s.SymmetricDifference(s): got %s", &s)
+	}
+	if n := len(s.DebugWords()); n != 0 {
+		t.Errorf("s.SymmetricDifference(s): got %d words, want 0", n)
+	}
+}
+
+func TestSymmetricDifferenceTrims(t *testing.T) {
+	t.Parallel()
+
+	var s1, s2 intset.IntSet[int]
+	s1.AddAll(1, 9, 1<<12)
+	s2.AddAll(2, 1<<12)
+
+	s1.SymmetricDifference(&s2)
+	if want, got := "{1 2 9}", s1.String(); !cmp.Equal(want, got) {
+		t.Error(cmp.Diff(want, got))
+	}
+	if n := len(s1.DebugWords()); n != 1 {
+		t.Errorf("SymmetricDifference zeroing the top word: got %d words, want 1", n)
+	}
+	if m := s1.Max(); m != 9 {
+		t.Errorf("SymmetricDifference zeroing the top word: Max got %d, want 9", m)
+	}
+}
+
 func TestHas(t *testing.T) {
 	t.Parallel()
 
@@ -671,101 +1022,416 @@ func TestCopy(t *testing.T) {
 	}
 }
 
-func TestString(t *testing.T) {
+func TestClone(t *testing.T) {
 	t.Parallel()
 
 	var s intset.IntSet[int]
-	s.Add(1)
-	s.Add(144)
+	s.AddAll(1, 9, 144)
+
+	// Assigning an IntSet value shares its words, so mutating
+	// the copy also changes the original: use Copy or Clone instead.
+	assigned := s
+	assigned.Remove(9)
+	if s.Has(9) {
+		t.Error("value assignment did not share storage")
+	}
+
 	s.Add(9)
+	c := s.Clone()
+	if !c.Equals(&s) {
+		t.Errorf("Clone: got %s, want %s", c, &s)
+	}
 
-	want := "{1 9 144}"
-	got := s.String()
-	if !cmp.Equal(want, got) {
-		t.Error(cmp.Diff(want, got))
+	c.Remove(9)
+	if !s.Has(9) {
+		t.Error("mutating Clone result changed the set")
 	}
 }
 
-func TestBitString(t *testing.T) {
+func TestMap(t *testing.T) {
 	t.Parallel()
 
-	testcases := []struct {
-		s    []int
+	var s intset.IntSet[int]
+	s.AddAll(1, 9, 144)
+
+	for _, tc := range []struct {
+		name string
+		f    func(int) int
 		want string
 	}{
-		{nil, "0"},
-		{[]int{0}, "1"},
-		{[]int{0, 4, 5}, "110001"},
-		{[]int{0, 7, 177}, "1" + strings.Repeat("0", 169) + "10000001"},
-		{[]int{0, 4, 5}, "110001"},
+		{"identity", func(x int) int { return x }, "{1 9 144}"},
+		{"shift", func(x int) int { return x + 1000 }, "{1001 1009 1144}"},
+		{"collide", func(x int) int { return x % 8 }, "{0 1}"},
+		{"reorder", func(x int) int { return 200 - x }, "{56 191 199}"},
+	} {
+		if got := s.Map(tc.f).String(); got != tc.want {
+			t.Errorf("Map(%s): got %s, want %s", tc.name, got, tc.want)
+		}
 	}
 
-	for _, tc := range testcases {
+	if want, got := "{1 9 144}", s.String(); !cmp.Equal(want, got) {
+		t.Errorf("Map changed the set: %s", cmp.Diff(want, got))
+	}
+
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 100; i++ {
 		var s intset.IntSet[int]
-		for _, x := range tc.s {
-			s.Add(x)
+		s.AddAll(randValues(r)...)
+		if got := s.Map(func(x int) int { return x }); !got.Equals(&s) {
+			t.Fatalf("%s.Map(identity): got %s", &s, got)
 		}
+	}
+}
 
-		got := s.BitString()
-		if !cmp.Equal(tc.want, got) {
-			t.Error(cmp.Diff(tc.want, got))
+func TestFilter(t *testing.T) {
+	t.Parallel()
+
+	preds := []struct {
+		name string
+		pred func(int) bool
+	}{
+		{"all", func(int) bool { return true }},
+		{"none", func(int) bool { return false }},
+		{"even", func(x int) bool { return x%2 == 0 }},
+	}
+
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 100; i++ {
+		var s intset.IntSet[int]
+		s.AddAll(randValues(r)...)
+		before := s.String()
+
+		for _, p := range preds {
+			var want []int
+			for _, x := range s.Elems() {
+				if p.pred(x) {
+					want = append(want, x)
+				}
+			}
+
+			got := s.Filter(p.pred)
+			if !cmp.Equal(want, got.Elems(), cmpopts.EquateEmpty()) {
+				t.Fatalf("%s.Filter(%s): %s", &s, p.name, cmp.Diff(want, got.Elems(), cmpopts.EquateEmpty()))
+			}
+			if len(want) == 0 && len(got.DebugWords()) != 0 {
+				t.Fatalf("%s.Filter(%s): empty result has %d words", &s, p.name, len(got.DebugWords()))
+			}
+		}
+
+		if s.String() != before {
+			t.Fatalf("Filter changed the set from %s to %s", before, &s)
 		}
 	}
 }
 
-func TestLowerBound(t *testing.T) {
+func TestPartition(t *testing.T) {
 	t.Parallel()
 
-	var s1 intset.IntSet[int]
-	s1.Add(1)
-	s1.Add(144)
-	s1.Add(9)
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 100; i++ {
+		var s intset.IntSet[int]
+		s.AddAll(randValues(r)...)
+		k := 1 + r.Intn(5)
+		pred := func(x int) bool { return x%k == 0 }
 
-	want := 1
-	got := s1.LowerBound(1)
-	if want != got {
-		t.Errorf("expected lowerBound %d, got %d", want, got)
-	}
+		in, out := s.Partition(pred)
+		if in.Intersects(out) {
+			t.Fatalf("%s.Partition: %s and %s intersect", &s, in, out)
+		}
 
-	want = 9
-	got = s1.LowerBound(8)
-	if want != got {
-		t.Errorf("expected lowerBound %d, got %d", want, got)
+		for x := range in.All() {
+			if !pred(x) {
+				t.Fatalf("%s.Partition: %d in the wrong set", &s, x)
+			}
+		}
+		for x := range out.All() {
+			if pred(x) {
+				t.Fatalf("%s.Partition: %d in the wrong set", &s, x)
+			}
+		}
+
+		in.UnionWith(out)
+		if !in.Equals(&s) {
+			t.Fatalf("%s.Partition: union of parts is %s", &s, in)
+		}
 	}
 
-	want = intset.MaxInt
-	got = s1.LowerBound(145)
-	if want != got {
-		t.Errorf("expected lowerBound %d, got %d", want, got)
+	var s intset.IntSet[int]
+	s.AddAll(1, 9, 144)
+	in, out := s.Partition(func(int) bool { return true })
+	if !in.Equals(&s) || !out.IsEmpty() || len(out.DebugWords()) != 0 {
+		t.Errorf("Partition(all): got %s and %s", in, out)
 	}
 }
 
-func TestUnionWith(t *testing.T) {
+func TestReduce(t *testing.T) {
 	t.Parallel()
 
-	var s1, s2 intset.IntSet[int]
-	s1.Add(1)
-	s1.Add(144)
-	s1.Add(9)
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 100; i++ {
+		var s intset.IntSet[int]
+		s.AddAll(randValues(r)...)
 
-	s2.Add(9)
-	s2.Add(42)
+		sum, count := 0, 0
+		for _, x := range s.Elems() {
+			sum += x
+			count++
+		}
 
-	want := "{1 9 42 144}"
-	s1.UnionWith(&s2)
-	got := s1.String()
-	if !cmp.Equal(want, got) {
-		t.Error(cmp.Diff(want, got))
+		if got := intset.Reduce(&s, 0, func(acc, x int) int { return acc + x }); got != sum {
+			t.Errorf("Reduce(sum): got %d, want %d", got, sum)
+		}
+		if got := intset.Reduce(&s, 0, func(acc, _ int) int { return acc + 1 }); got != count {
+			t.Errorf("Reduce(count): got %d, want %d", got, count)
+		}
+
+		// Elements are folded in ascending order.
+		got := intset.Reduce(&s, []int(nil), func(acc []int, x int) []int { return append(acc, x) })
+		if want := s.Elems(); !cmp.Equal(want, got, cmpopts.EquateEmpty()) {
+			t.Fatal(cmp.Diff(want, got, cmpopts.EquateEmpty()))
+		}
 	}
 }
 
-func TestIntersectWith(t *testing.T) {
+func TestCopyInto(t *testing.T) {
 	t.Parallel()
 
-	var s1, s2 intset.IntSet[int]
-	s1.Add(1)
-	s1.Add(144)
-	s1.Add(9)
+	var s intset.IntSet[int]
+	s.AddAll(1, 144, 9)
+
+	for _, dst := range []*intset.IntSet[int]{
+		new(intset.IntSet[int]),
+		intset.NewWithCapacity[int](1000),
+		intset.FromBits[int](0xff),
+	} {
+		s.CopyInto(dst)
+		if !dst.Equals(&s) {
+			t.Errorf("CopyInto: got %s, want %s", dst, &s)
+		}
+
+		dst.Add(2)
+		dst.Remove(144)
+		if want, got := "{1 9 144}", s.String(); !cmp.Equal(want, got) {
+			t.Errorf("source changed after mutating dst: %s", cmp.Diff(want, got))
+		}
+	}
+
+	// Shrinking dst must not leave stale words behind.
+	var big intset.IntSet[int]
+	big.AddAll(1, 1000)
+	var small intset.IntSet[int]
+	small.Add(3)
+	small.CopyInto(&big)
+	if want, got := "{3}", big.String(); !cmp.Equal(want, got) {
+		t.Error(cmp.Diff(want, got))
+	}
+
+	s.CopyInto(&s)
+	if want, got := "{1 9 144}", s.String(); !cmp.Equal(want, got) {
+		t.Error(cmp.Diff(want, got))
+	}
+}
+
+func TestString(t *testing.T) {
+	t.Parallel()
+
+	var s intset.IntSet[int]
+	s.Add(1)
+	s.Add(144)
+	s.Add(9)
+
+	want := "{1 9 144}"
+	got := s.String()
+	if !cmp.Equal(want, got) {
+		t.Error(cmp.Diff(want, got))
+	}
+}
+
+func TestFormat(t *testing.T) {
+	t.Parallel()
+
+	var s, empty intset.IntSet[int]
+	s.AddAll(144, 1, 9)
+
+	for _, tc := range []struct {
+		s               *intset.IntSet[int]
+		open, sep, shut string
+		want            string
+	}{
+		{&s, "{", " ", "}", "{1 9 144}"},
+		{&s, "[", ", ", "]", "[1, 9, 144]"},
+		{&s, "", ",", "", "1,9,144"},
+		{&s, "<", "", ">", "<19144>"},
+		{&empty, "[", ", ", "]", "[]"},
+		{&empty, "", ",", "", ""},
+	} {
+		if got := tc.s.Format(tc.open, tc.sep, tc.shut); got != tc.want {
+			t.Errorf("%s.Format(%q, %q, %q): got %q, want %q", tc.s, tc.open, tc.sep, tc.shut, got, tc.want)
+		}
+	}
+
+	var keys KeySet
+	keys.AddAll(Crystal, Copper)
+	if want, got := "copper|crystal", keys.Format("", "|", ""); !cmp.Equal(want, got) {
+		t.Error(cmp.Diff(want, got))
+	}
+}
+
+func TestAppendString(t *testing.T) {
+	t.Parallel()
+
+	r := rand.New(rand.NewSource(1))
+	sets := [][]int{nil, {0}, {1, 9, 144}}
+	for i := 0; i < 100; i++ {
+		sets = append(sets, randValues(r))
+	}
+
+	for _, xs := range sets {
+		var s intset.IntSet[int]
+		s.AddAll(xs...)
+
+		prefix := []byte("set=")
+		got := s.AppendString(prefix[:len(prefix):len(prefix)])
+		if want := "set=" + s.String(); string(got) != want {
+			t.Fatalf("AppendString: got %q, want %q", got, want)
+		}
+	}
+
+	var keys KeySet
+	keys.AddAll(Jade, Copper)
+	if want, got := "keys: {copper jade}", string(keys.AppendString([]byte("keys: "))); !cmp.Equal(want, got) {
+		t.Error(cmp.Diff(want, got))
+	}
+}
+
+func TestBitString(t *testing.T) {
+	t.Parallel()
+
+	testcases := []struct {
+		s    []int
+		want string
+	}{
+		{nil, "0"},
+		{[]int{0}, "1"},
+		{[]int{0, 4, 5}, "110001"},
+		{[]int{0, 7, 177}, "1" + strings.Repeat("0", 169) + "10000001"},
+		{[]int{0, 4, 5}, "110001"},
+	}
+
+	for _, tc := range testcases {
+		var s intset.IntSet[int]
+		for _, x := range tc.s {
+			s.Add(x)
+		}
+
+		got := s.BitString()
+		if !cmp.Equal(tc.want, got) {
+			t.Error(cmp.Diff(tc.want, got))
+		}
+	}
+}
+
+func TestAppendBitString(t *testing.T) {
+	t.Parallel()
+
+	r := rand.New(rand.NewSource(1))
+	sets := [][]int{nil, {0}, {1, 9, 144}}
+	for i := 0; i < 100; i++ {
+		sets = append(sets, randValues(r))
+	}
+
+	for _, xs := range sets {
+		var s intset.IntSet[int]
+		s.AddAll(xs...)
+
+		prefix := []byte("bits=")
+		got := s.AppendBitString(prefix[:len(prefix):len(prefix)])
+		if want := "bits=" + s.BitString(); string(got) != want {
+			t.Fatalf("AppendBitString: got %q, want %q", got, want)
+		}
+
+		if got := string(s.AppendBitString(nil)); got != s.BitString() {
+			t.Fatalf("AppendBitString(nil): got %q, want %q", got, s.BitString())
+		}
+	}
+}
+
+func TestLowerBound(t *testing.T) {
+	t.Parallel()
+
+	var s1 intset.IntSet[int]
+	s1.Add(1)
+	s1.Add(144)
+	s1.Add(9)
+
+	want := 1
+	got := s1.LowerBound(1)
+	if want != got {
+		t.Errorf("expected lowerBound %d, got %d", want, got)
+	}
+
+	want = 9
+	got = s1.LowerBound(8)
+	if want != got {
+		t.Errorf("expected lowerBound %d, got %d", want, got)
+	}
+
+	want = intset.MaxInt
+	got = s1.LowerBound(145)
+	if want != got {
+		t.Errorf("expected lowerBound %d, got %d", want, got)
+	}
+}
+
+func TestLowerBoundLaterWord(t *testing.T) {
+	t.Parallel()
+
+	wordSize := 32 << (^uint(0) >> 63)
+
+	// The word holding x has elements only below x, so the result
+	// comes from a later word, past an empty one.
+	var s intset.IntSet[int]
+	s.AddAll(1, 3, 3*wordSize+5, 3*wordSize+7)
+
+	for _, tc := range []struct{ x, want int }{
+		{4, 3*wordSize + 5},
+		{wordSize - 1, 3*wordSize + 5},
+		{3*wordSize + 5, 3*wordSize + 5},
+		{3*wordSize + 6, 3*wordSize + 7},
+		{3*wordSize + 8, intset.MaxInt},
+	} {
+		if got := s.LowerBound(tc.x); got != tc.want {
+			t.Errorf("%s.LowerBound(%d): got %d, want %d", &s, tc.x, got, tc.want)
+		}
+	}
+}
+
+func TestUnionWith(t *testing.T) {
+	t.Parallel()
+
+	var s1, s2 intset.IntSet[int]
+	s1.Add(1)
+	s1.Add(144)
+	s1.Add(9)
+
+	s2.Add(9)
+	s2.Add(42)
+
+	want := "{1 9 42 144}"
+	s1.UnionWith(&s2)
+	got := s1.String()
+	if !cmp.Equal(want, got) {
+		t.Error(cmp.Diff(want, got))
+	}
+}
+
+func TestIntersectWith(t *testing.T) {
+	t.Parallel()
+
+	var s1, s2 intset.IntSet[int]
+	s1.Add(1)
+	s1.Add(144)
+	s1.Add(9)
 
 	s2.Add(9)
 	s2.Add(42)
@@ -796,3 +1462,2381 @@ func TestDifferenceWith(t *testing.T) {
 		t.Error(cmp.Diff(want, got))
 	}
 }
+
+func TestNotInAll(t *testing.T) {
+	t.Parallel()
+
+	const universe = 300
+
+	// notInAll computes the result by intersecting and then complementing.
+	notInAll := func(sets ...*intset.IntSet[int]) *intset.IntSet[int] {
+		r := new(intset.IntSet[int])
+		if len(sets) == 0 {
+			return r
+		}
+
+		in := sets[0].Copy()
+		for _, s := range sets[1:] {
+			in.IntersectWith(s)
+		}
+
+		for x := 0; x < universe; x++ {
+			if !in.Has(x) {
+				r.Add(x)
+			}
+		}
+
+		return r
+	}
+
+	r := rand.New(rand.NewSource(1))
+	randSet := func() *intset.IntSet[int] {
+		s := new(intset.IntSet[int])
+		for i := 0; i < 200; i++ {
+			s.Add(r.Intn(universe + 100))
+		}
+		return s
+	}
+
+	testcases := [][]*intset.IntSet[int]{
+		nil,
+		{new(intset.IntSet[int])},
+		{randSet()},
+		{randSet(), randSet()},
+		{randSet(), randSet(), randSet()},
+	}
+
+	for i, sets := range testcases {
+		want := notInAll(sets...)
+		got := intset.NotInAll(universe, sets...)
+		if !got.Equals(want) {
+			t.Errorf("NotInAll #%d: got %s, want %s", i, got, want)
+		}
+	}
+
+	if got := intset.NotInAll(0, randSet()); !got.IsEmpty() {
+		t.Errorf("NotInAll(0): got %s, want {}", got)
+	}
+
+	// Every set holding the whole universe leaves nothing, in no words.
+	full := new(intset.IntSet[int])
+	full.AddRange(0, universe)
+	if got := intset.NotInAll(universe, full, full); got.WordCount() != 0 {
+		t.Errorf("NotInAll of full sets: got %d words, want 0", got.WordCount())
+	}
+}
+
+func TestSwap(t *testing.T) {
+	t.Parallel()
+
+	var s1, s2 intset.IntSet[int]
+	s1.AddAll(1, 144, 9)
+	s2.AddAll(42, 1000)
+
+	s1.Swap(&s2)
+
+	if want, got := "{42 1000}", s1.String(); !cmp.Equal(want, got) {
+		t.Error(cmp.Diff(want, got))
+	}
+	if want, got := "{1 9 144}", s2.String(); !cmp.Equal(want, got) {
+		t.Error(cmp.Diff(want, got))
+	}
+
+	s1.Add(2)
+	s2.Remove(9)
+
+	if want, got := "{2 42 1000}", s1.String(); !cmp.Equal(want, got) {
+		t.Error(cmp.Diff(want, got))
+	}
+	if want, got := "{1 144}", s2.String(); !cmp.Equal(want, got) {
+		t.Error(cmp.Diff(want, got))
+	}
+}
+
+func TestCosine(t *testing.T) {
+	t.Parallel()
+
+	testcases := []struct {
+		s, t []int
+		want float64
+	}{
+		{nil, nil, 0},
+		{[]int{1, 2}, nil, 0},
+		{[]int{1, 9, 144}, []int{1, 9, 144}, 1},
+		{[]int{1, 9, 144}, []int{2, 42, 1000}, 0},
+		{[]int{1, 2, 3, 4}, []int{3, 4, 200}, 2 / math.Sqrt(12)},
+		{[]int{0, 100}, []int{100, 200, 300, 400}, 1 / math.Sqrt(8)},
+	}
+
+	for _, tc := range testcases {
+		var s1, s2 intset.IntSet[int]
+		s1.AddAll(tc.s...)
+		s2.AddAll(tc.t...)
+
+		if got := s1.Cosine(&s2); math.Abs(got-tc.want) > 1e-12 {
+			t.Errorf("%s.Cosine(%s): got %v, want %v", &s1, &s2, got, tc.want)
+		}
+	}
+}
+
+// TestOpInto is not parallel since it uses testing.AllocsPerRun.
+func TestOpInto(t *testing.T) {
+	testcases := []struct {
+		name string
+		into func(s, dst, t *intset.IntSet[int])
+		with func(s, t *intset.IntSet[int])
+	}{
+		{"Union", (*intset.IntSet[int]).UnionInto, (*intset.IntSet[int]).UnionWith},
+		{"Intersect", (*intset.IntSet[int]).IntersectInto, (*intset.IntSet[int]).IntersectWith},
+		{"Difference", (*intset.IntSet[int]).DifferenceInto, (*intset.IntSet[int]).DifferenceWith},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := rand.New(rand.NewSource(1))
+			var dst intset.IntSet[int]
+			for i := 0; i < 100; i++ {
+				var s1, s2 intset.IntSet[int]
+				s1.AddAll(randValues(r)...)
+				s2.AddAll(randValues(r)...)
+
+				want := s1.Copy()
+				tc.with(want, &s2)
+
+				tc.into(&s1, &dst, &s2)
+				if !dst.Equals(want) {
+					t.Fatalf("%sInto: got %s, want %s", tc.name, &dst, want)
+				}
+
+				// dst may alias either operand.
+				s1c, s2c := s1.Copy(), s2.Copy()
+				tc.into(s1c, s1c, &s2)
+				tc.into(&s1, s2c, s2c)
+				if !s1c.Equals(want) || !s2c.Equals(want) {
+					t.Fatalf("%sInto aliased: got %s and %s, want %s", tc.name, s1c, s2c, want)
+				}
+			}
+
+			var s1, s2 intset.IntSet[int]
+			s1.AddAll(1, 144, 9, 1000)
+			s2.AddAll(9, 42, 2000)
+			tc.into(&s1, &dst, &s2) // warm up
+			if n := testing.AllocsPerRun(100, func() { tc.into(&s1, &dst, &s2) }); n != 0 {
+				t.Errorf("%sInto: got %v allocs, want 0", tc.name, n)
+			}
+		})
+	}
+}
+
+func TestIsDense(t *testing.T) {
+	t.Parallel()
+
+	testcases := []struct {
+		s         []int
+		threshold float64
+		want      bool
+	}{
+		{nil, 0, false},
+		{[]int{0}, 1, true},
+		{[]int{3}, 0.25, true},
+		{[]int{3}, 0.26, false},
+		{[]int{0, 1, 2, 3}, 1, true},
+		{[]int{0, 1, 3}, 0.75, true},
+		{[]int{0, 1, 3}, 0.76, false},
+		{[]int{1, 199}, 0.01, true},
+		{[]int{1, 199}, 0.011, false},
+	}
+
+	for _, tc := range testcases {
+		var s intset.IntSet[int]
+		s.AddAll(tc.s...)
+
+		if got := s.IsDense(tc.threshold); got != tc.want {
+			t.Errorf("%s.IsDense(%v): got %t, want %t", &s, tc.threshold, got, tc.want)
+		}
+	}
+}
+
+func TestForEachCoord(t *testing.T) {
+	t.Parallel()
+
+	var s intset.IntSet[int]
+	s.AddAll(0, 1, 9, 63, 64, 144, 1000)
+
+	wordSize := 32 << (^uint(0) >> 63)
+
+	var got []int
+	s.ForEachCoord(func(x, word int, bit uint) bool {
+		if y := word*wordSize + int(bit); y != x {
+			t.Errorf("ForEachCoord(%d): coordinates (%d, %d) give %d", x, word, bit, y)
+		}
+
+		got = append(got, x)
+		return true
+	})
+
+	if want := s.Elems(); !cmp.Equal(want, got) {
+		t.Error(cmp.Diff(want, got))
+	}
+
+	got = got[:0]
+	s.ForEachCoord(func(x, _ int, _ uint) bool {
+		got = append(got, x)
+		return x < 63
+	})
+
+	if want := []int{0, 1, 9, 63}; !cmp.Equal(want, got) {
+		t.Errorf("ForEachCoord early stop: %s", cmp.Diff(want, got))
+	}
+}
+
+func TestWithRange(t *testing.T) {
+	t.Parallel()
+
+	testcases := []struct {
+		s      []int
+		lo, hi int
+		want   string
+	}{
+		{nil, 0, 0, "{}"},
+		{nil, 3, 6, "{3 4 5}"},
+		{[]int{1, 144}, 5, 3, "{1 144}"},
+		{[]int{1, 144}, 62, 66, "{1 62 63 64 65 144}"},
+		{[]int{1, 4, 144}, 3, 6, "{1 3 4 5 144}"},
+	}
+
+	for _, tc := range testcases {
+		var s intset.IntSet[int]
+		s.AddAll(tc.s...)
+		before := s.String()
+
+		got := s.WithRange(tc.lo, tc.hi).String()
+		if !cmp.Equal(tc.want, got) {
+			t.Errorf("%s.WithRange(%d, %d): %s", before, tc.lo, tc.hi, cmp.Diff(tc.want, got))
+		}
+
+		if after := s.String(); after != before {
+			t.Errorf("WithRange(%d, %d) mutated source: got %s, want %s", tc.lo, tc.hi, after, before)
+		}
+	}
+}
+
+func TestMinIntervals(t *testing.T) {
+	t.Parallel()
+
+	testcases := []struct {
+		s    []int
+		want [][2]int
+	}{
+		{nil, nil},
+		{[]int{5}, [][2]int{{5, 5}}},
+		{[]int{1, 2, 3, 7, 9, 10}, [][2]int{{1, 3}, {7, 7}, {9, 10}}},
+		{[]int{62, 63, 64, 65, 127, 128}, [][2]int{{62, 65}, {127, 128}}},
+	}
+
+	for _, tc := range testcases {
+		var s intset.IntSet[int]
+		s.AddAll(tc.s...)
+
+		got := s.MinIntervals()
+		if !cmp.Equal(tc.want, got) {
+			t.Errorf("%s.MinIntervals: %s", &s, cmp.Diff(tc.want, got))
+		}
+
+		if n := s.MinIntervalCount(); n != len(tc.want) {
+			t.Errorf("%s.MinIntervalCount: got %d, want %d", &s, n, len(tc.want))
+		}
+	}
+
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 100; i++ {
+		var s intset.IntSet[int]
+		for _, x := range randValues(r) {
+			for n := r.Intn(100); n >= 0; n-- {
+				s.Add(x + n)
+			}
+		}
+
+		var covered intset.IntSet[int]
+		intervals := s.MinIntervals()
+		for j, iv := range intervals {
+			if iv[0] > iv[1] {
+				t.Fatalf("MinIntervals: empty interval %v", iv)
+			}
+
+			// Sorted, disjoint and non-adjacent.
+			if j > 0 && iv[0] <= intervals[j-1][1]+1 {
+				t.Fatalf("MinIntervals: interval %v not after %v", iv, intervals[j-1])
+			}
+
+			for x := iv[0]; x <= iv[1]; x++ {
+				covered.Add(x)
+			}
+		}
+
+		if !covered.Equals(&s) {
+			t.Fatalf("MinIntervals: intervals cover %s, want %s", &covered, &s)
+		}
+	}
+
+	// A run ending at the largest value of E is reported exactly.
+	var s8 intset.IntSet[int8]
+	s8.AddAll(120, 121, 122, 123, 124, 125, 126)
+	if want, got := [][2]int8{{120, 126}}, s8.MinIntervals(); !cmp.Equal(want, got) {
+		t.Errorf("%s.MinIntervals: %s", &s8, cmp.Diff(want, got))
+	}
+	s8.Add(127)
+	if want, got := [][2]int8{{120, 127}}, s8.MinIntervals(); !cmp.Equal(want, got) {
+		t.Errorf("%s.MinIntervals: %s", &s8, cmp.Diff(want, got))
+	}
+
+	var u8 intset.IntSet[uint8]
+	u8.AddAll(0, 3, 255)
+	if want, got := [][2]uint8{{0, 0}, {3, 3}, {255, 255}}, u8.MinIntervals(); !cmp.Equal(want, got) {
+		t.Errorf("%s.MinIntervals: %s", &u8, cmp.Diff(want, got))
+	}
+}
+
+func TestShiftRight(t *testing.T) {
+	t.Parallel()
+
+	shiftRight := func(s *intset.IntSet[int], k int) *intset.IntSet[int] {
+		r := new(intset.IntSet[int])
+		for _, x := range s.Elems() {
+			if x >= k {
+				r.Add(x - k)
+			}
+		}
+		return r
+	}
+
+	var s intset.IntSet[int]
+	s.AddAll(0, 1, 9, 63, 64, 65, 144, 1000)
+
+	for _, k := range []int{0, 1, 2, 9, 10, 63, 64, 65, 100, 128, 999, 1000, 1001, 5000} {
+		want := shiftRight(&s, k)
+
+		got := s.Copy()
+		got.ShiftRight(k)
+		if !got.Equals(want) {
+			t.Errorf("%s.ShiftRight(%d): got %s, want %s", &s, k, got, want)
+		}
+		if words := got.DebugWords(); len(words) > 0 && words[len(words)-1] == 0 {
+			t.Errorf("%s.ShiftRight(%d) left trailing zero words: %v", &s, k, words)
+		}
+	}
+
+	var high intset.IntSet[int]
+	high.Add(64)
+	high.ShiftRight(1)
+	if words := high.DebugWords(); len(words) != 1 {
+		t.Errorf("{64}.ShiftRight(1): got words %v, want one word", words)
+	}
+
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 100; i++ {
+		var s intset.IntSet[int]
+		s.AddAll(randValues(r)...)
+		k := r.Intn(1 << 12)
+
+		want := shiftRight(&s, k)
+		s.ShiftRight(k)
+		if !s.Equals(want) {
+			t.Fatalf("ShiftRight(%d): got %s, want %s", k, &s, want)
+		}
+	}
+}
+
+func TestRunLengthHistogram(t *testing.T) {
+	t.Parallel()
+
+	testcases := []struct {
+		s    []int
+		want map[int]int
+	}{
+		{nil, map[int]int{}},
+		{[]int{5}, map[int]int{1: 1}},
+		{[]int{1, 2, 3, 7, 9, 10, 20, 21, 22}, map[int]int{1: 1, 2: 1, 3: 2}},
+		{[]int{62, 63, 64, 65, 100, 127, 128}, map[int]int{1: 1, 2: 1, 4: 1}},
+	}
+
+	for _, tc := range testcases {
+		var s intset.IntSet[int]
+		s.AddAll(tc.s...)
+
+		got := s.RunLengthHistogram()
+		if !cmp.Equal(tc.want, got) {
+			t.Errorf("%s.RunLengthHistogram: %s", &s, cmp.Diff(tc.want, got))
+		}
+	}
+
+	var s intset.IntSet[int]
+	for x := 0; x < 200; x++ {
+		s.Add(x)
+	}
+	if want, got := map[int]int{200: 1}, s.RunLengthHistogram(); !cmp.Equal(want, got) {
+		t.Errorf("RunLengthHistogram across words: %s", cmp.Diff(want, got))
+	}
+}
+
+func TestSymmetricDifferencePerWord(t *testing.T) {
+	t.Parallel()
+
+	wordSize := 32 << (^uint(0) >> 63)
+
+	var s1, s2 intset.IntSet[int]
+	for x := 0; x < 4*wordSize; x += 2 {
+		s1.Add(x)
+		s2.Add(x)
+	}
+
+	// Diverge only in words 1 and 3, plus a word only s2 has.
+	s1.Add(wordSize + 1)
+	s1.Add(wordSize + 3)
+	s2.Remove(3 * wordSize)
+	s2.Add(5*wordSize + 7)
+
+	want := []int{0, 2, 0, 1, 0, 1}
+	if got := s1.SymmetricDifferencePerWord(&s2); !cmp.Equal(want, got) {
+		t.Error(cmp.Diff(want, got))
+	}
+	if got := s2.SymmetricDifferencePerWord(&s1); !cmp.Equal(want, got) {
+		t.Error(cmp.Diff(want, got))
+	}
+
+	var empty intset.IntSet[int]
+	if got := empty.SymmetricDifferencePerWord(&empty); len(got) != 0 {
+		t.Errorf("{}.SymmetricDifferencePerWord({}): got %v, want []", got)
+	}
+
+	// Equal sets give the same result whatever words they have allocated.
+	padded := s1.Copy()
+	intset.SetWords(padded, append(padded.DebugWords(), 0, 0, 0, 0))
+	if got := padded.SymmetricDifferencePerWord(&s2); !cmp.Equal(want, got) {
+		t.Errorf("with trailing zero words: %s", cmp.Diff(want, got))
+	}
+
+	var zeros intset.IntSet[int]
+	intset.SetWords(&zeros, make([]uint, 8))
+	if got := zeros.SymmetricDifferencePerWord(&empty); len(got) != 0 {
+		t.Errorf("{}.SymmetricDifferencePerWord({}) with zero words: got %v, want []", got)
+	}
+}
+
+func TestAll(t *testing.T) {
+	t.Parallel()
+
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 100; i++ {
+		var s intset.IntSet[int]
+		s.AddAll(randValues(r)...)
+
+		var got []int
+		for x := range s.All() {
+			got = append(got, x)
+		}
+
+		if want := s.Elems(); !cmp.Equal(want, got, cmpopts.EquateEmpty()) {
+			t.Fatal(cmp.Diff(want, got, cmpopts.EquateEmpty()))
+		}
+	}
+
+	var s intset.IntSet[int]
+	s.AddAll(1, 9, 144, 1000)
+
+	var got []int
+	for x := range s.All() {
+		if x > 100 {
+			break
+		}
+		got = append(got, x)
+	}
+
+	if want := []int{1, 9}; !cmp.Equal(want, got) {
+		t.Errorf("All with break: %s", cmp.Diff(want, got))
+	}
+}
+
+func TestBackward(t *testing.T) {
+	t.Parallel()
+
+	r := rand.New(rand.NewSource(1))
+	sets := [][]int{nil, {0}, {1000}}
+	for i := 0; i < 100; i++ {
+		sets = append(sets, randValues(r))
+	}
+
+	for _, xs := range sets {
+		var s intset.IntSet[int]
+		s.AddAll(xs...)
+
+		var got []int
+		for x := range s.Backward() {
+			got = append(got, x)
+		}
+
+		want := s.Elems()
+		for i, j := 0, len(want)-1; i < j; i, j = i+1, j-1 {
+			want[i], want[j] = want[j], want[i]
+		}
+
+		if !cmp.Equal(want, got, cmpopts.EquateEmpty()) {
+			t.Fatal(cmp.Diff(want, got, cmpopts.EquateEmpty()))
+		}
+	}
+
+	var s intset.IntSet[int]
+	s.AddAll(1, 9, 144, 1000)
+
+	var got []int
+	for x := range s.Backward() {
+		if x < 100 {
+			break
+		}
+		got = append(got, x)
+	}
+
+	if want := []int{1000, 144}; !cmp.Equal(want, got) {
+		t.Errorf("Backward with break: %s", cmp.Diff(want, got))
+	}
+}
+
+func TestDebugWords(t *testing.T) {
+	t.Parallel()
+
+	wordSize := 32 << (^uint(0) >> 63)
+
+	var s intset.IntSet[int]
+	s.AddAll(0, 3, wordSize+1)
+
+	want := []uint{1<<0 | 1<<3, 1 << 1}
+	got := s.DebugWords()
+	if !cmp.Equal(want, got) {
+		t.Error(cmp.Diff(want, got))
+	}
+
+	got[0] = 0
+	if !s.Has(0) {
+		t.Error("mutating DebugWords result changed the set")
+	}
+
+	// Shifting out every element leaves no words behind.
+	s.ShiftRight(2 * wordSize)
+	if words := s.DebugWords(); len(words) != 0 {
+		t.Errorf("ShiftRight past Max: got words %v, want none", words)
+	}
+
+	s.AddAll(1, 5*wordSize)
+	s.Clear()
+	if words := s.DebugWords(); len(words) != 0 {
+		t.Errorf("Clear: got words %v, want none", words)
+	}
+}
+
+func TestWords(t *testing.T) {
+	t.Parallel()
+
+	wordSize := 32 << (^uint(0) >> 63)
+
+	var s intset.IntSet[int]
+	if words := s.Words(); len(words) != 0 {
+		t.Errorf("Words of empty set: got %v", words)
+	}
+
+	s.AddAll(0, 3, wordSize+1, 3*wordSize)
+	s.RemoveAll(3 * wordSize) // leaves no trailing zero words in Words
+
+	want := []uint{1<<0 | 1<<3, 1 << 1}
+	got := s.Words()
+	if !cmp.Equal(want, got) {
+		t.Error(cmp.Diff(want, got))
+	}
+
+	got[0] = 0
+	got[1] = ^uint(0)
+	if want, got := fmt.Sprintf("{0 3 %d}", wordSize+1), s.String(); !cmp.Equal(want, got) {
+		t.Errorf("mutating Words result changed the set: %s", cmp.Diff(want, got))
+	}
+
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 100; i++ {
+		s.Clear()
+		s.AddAll(randValues(r)...)
+
+		words := s.Words()
+		for x := 0; x < len(words)*wordSize+wordSize; x++ {
+			inWords := x/wordSize < len(words) && words[x/wordSize]&(1<<(x%wordSize)) != 0
+			if bit, has := s.Bit(x), s.Has(x); bit != has || bit != inWords {
+				t.Fatalf("%s: Bit(%d) = %t, Has = %t, Words = %t", &s, x, bit, has, inWords)
+			}
+		}
+	}
+}
+
+func TestFromWords(t *testing.T) {
+	t.Parallel()
+
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 100; i++ {
+		var s intset.IntSet[int]
+		s.AddAll(randValues(r)...)
+
+		if got := intset.FromWords[int](s.Words()); !got.Equals(&s) {
+			t.Fatalf("FromWords(%s.Words()): got %s", &s, got)
+		}
+	}
+
+	words := []uint{1<<0 | 1<<3, 0, 0}
+	s := intset.FromWords[int](words)
+	if n := len(s.DebugWords()); n != 1 {
+		t.Errorf("FromWords with trailing zero words: got %d words, want 1", n)
+	}
+
+	words[0] = 1 << 5
+	if want, got := "{0 3}", s.String(); !cmp.Equal(want, got) {
+		t.Errorf("FromWords aliases its input: %s", cmp.Diff(want, got))
+	}
+
+	s.Add(1)
+	if words[0] != 1<<5 {
+		t.Errorf("FromWords aliases its input: words[0] = %#x", words[0])
+	}
+
+	if s := intset.FromWords[int](nil); !s.IsEmpty() {
+		t.Errorf("FromWords(nil): got %s", s)
+	}
+}
+
+func TestAddSlice(t *testing.T) {
+	t.Parallel()
+
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 100; i++ {
+		xs := randValues(r)
+
+		var want intset.IntSet[int]
+		want.AddAll(xs...)
+
+		if got := intset.FromSlice(xs); !got.Equals(&want) {
+			t.Fatalf("FromSlice(%v): got %s, want %s", xs, got, &want)
+		}
+
+		var got intset.IntSet[int]
+		got.AddAll(1, 9, 144)
+		want.AddAll(1, 9, 144)
+		got.AddSlice(xs)
+		if !got.Equals(&want) {
+			t.Fatalf("AddSlice(%v): got %s, want %s", xs, &got, &want)
+		}
+	}
+
+	if s := intset.FromSlice[int](nil); !s.IsEmpty() {
+		t.Errorf("FromSlice(nil): got %s", s)
+	}
+}
+
+func TestToBools(t *testing.T) {
+	t.Parallel()
+
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 100; i++ {
+		var s intset.IntSet[int]
+		s.AddAll(randValues(r)...)
+
+		n := r.Intn(1 << 13)
+		if i%10 == 0 && !s.IsEmpty() {
+			n = s.Max() // truncates the maximum element
+		}
+
+		got := s.ToBools(n)
+		if len(got) != n {
+			t.Fatalf("ToBools(%d): got length %d", n, len(got))
+		}
+
+		for x, b := range got {
+			if b != s.Has(x) {
+				t.Fatalf("%s.ToBools(%d)[%d]: got %t, want %t", &s, n, x, b, !b)
+			}
+		}
+	}
+
+	var s intset.IntSet[int]
+	s.AddAll(1, 9, 144)
+	if want, got := []bool{false, true, false}, s.ToBools(3); !cmp.Equal(want, got) {
+		t.Error(cmp.Diff(want, got))
+	}
+	if got := s.ToBools(0); len(got) != 0 {
+		t.Errorf("ToBools(0): got %v", got)
+	}
+}
+
+func TestFromBools(t *testing.T) {
+	t.Parallel()
+
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 100; i++ {
+		var s intset.IntSet[int]
+		s.AddAll(randValues(r)...)
+
+		n := r.Intn(1 << 13)
+		want := s.Copy()
+		want.RemoveRange(n, intset.MaxInt)
+
+		if got := intset.FromBools[int](s.ToBools(n)); !got.Equals(want) {
+			t.Fatalf("FromBools(%s.ToBools(%d)): got %s, want %s", &s, n, got, want)
+		}
+	}
+
+	if want, got := "{1 3}", intset.FromBools[int]([]bool{false, true, false, true, false}).String(); !cmp.Equal(want, got) {
+		t.Error(cmp.Diff(want, got))
+	}
+
+	s := intset.FromBools[int](make([]bool, 1000))
+	if n := len(s.DebugWords()); !s.IsEmpty() || n != 0 {
+		t.Errorf("FromBools of all false: got %s with %d words", s, n)
+	}
+}
+
+func TestBigInt(t *testing.T) {
+	t.Parallel()
+
+	r := rand.New(rand.NewSource(1))
+	sets := [][]int{nil, {0}, {1, 9, 144}, {1 << 16}}
+	for i := 0; i < 100; i++ {
+		sets = append(sets, randValues(r))
+	}
+
+	for _, xs := range sets {
+		var s intset.IntSet[int]
+		s.AddAll(xs...)
+
+		z := s.BigInt()
+		for x := 0; x < z.BitLen()+8; x++ {
+			if got := z.Bit(x) == 1; got != s.Has(x) {
+				t.Fatalf("%s.BigInt().Bit(%d): got %t", &s, x, got)
+			}
+		}
+
+		if got := intset.FromBigInt[int](z); !got.Equals(&s) {
+			t.Fatalf("FromBigInt(%s.BigInt()): got %s", &s, got)
+		}
+	}
+
+	z, _ := new(big.Int).SetString("100000000000000000000000000000101", 2)
+	if want, got := "{0 2 32}", intset.FromBigInt[int](z).String(); !cmp.Equal(want, got) {
+		t.Error(cmp.Diff(want, got))
+	}
+
+	defer func() {
+		if r := recover(); r == nil {
+			t.Error("FromBigInt(-1): no panic")
+		}
+	}()
+	intset.FromBigInt[int](big.NewInt(-1))
+}
+
+func TestStats(t *testing.T) {
+	t.Parallel()
+
+	wordSize := 32 << (^uint(0) >> 63)
+
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 100; i++ {
+		var s intset.IntSet[int]
+		s.AddAll(randValues(r)...)
+		s.Grow(r.Intn(1 << 14))
+
+		st := s.Stats()
+		if st.Elements != s.Len() {
+			t.Errorf("Stats: got %d elements, want %d", st.Elements, s.Len())
+		}
+		if want := intset.WordsCap(&s) * wordSize / 8; st.Bytes != want {
+			t.Errorf("Stats: got %d bytes, want %d", st.Bytes, want)
+		}
+		if want := len(s.DebugWords()); st.Words != want {
+			t.Errorf("Stats: got %d words, want %d", st.Words, want)
+		}
+		if st.Words > 0 {
+			if want := float64(st.Elements) / float64(st.Words*wordSize); st.Density != want {
+				t.Errorf("Stats: got density %g, want %g", st.Density, want)
+			}
+		}
+	}
+
+	var empty intset.IntSet[int]
+	if want, got := (intset.Stats{}), empty.Stats(); want != got {
+		t.Errorf("Stats of empty set: got %+v, want %+v", got, want)
+	}
+
+	full := intset.FromSlice([]int{0})
+	full.AddRange(0, wordSize)
+	if got := full.Stats().Density; got != 1 {
+		t.Errorf("Stats of full word: got density %g, want 1", got)
+	}
+}
+
+func TestAddIf(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		x       int
+		cond    bool
+		present bool
+		want    string
+	}{
+		{9, true, true, "{1 9 144}"},
+		{9, false, true, "{1 9 144}"},
+		{2, true, false, "{1 2 9 144}"},
+		{2, false, false, "{1 9 144}"},
+		{1000, true, false, "{1 9 144 1000}"},
+		{1000, false, false, "{1 9 144}"},
+	} {
+		var s intset.IntSet[int]
+		s.AddAll(1, 9, 144)
+
+		if got := s.AddIf(tc.x, tc.cond); got != tc.present {
+			t.Errorf("AddIf(%d, %t): got %t, want %t", tc.x, tc.cond, got, tc.present)
+		}
+		if got := s.String(); got != tc.want {
+			t.Errorf("AddIf(%d, %t): set is %s, want %s", tc.x, tc.cond, got, tc.want)
+		}
+	}
+}
+
+func TestWordCountAndCapBits(t *testing.T) {
+	t.Parallel()
+
+	wordSize := 32 << (^uint(0) >> 63)
+
+	var s intset.IntSet[int]
+	if n := s.WordCount(); n != 0 {
+		t.Errorf("WordCount of empty set: got %d", n)
+	}
+
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 100; i++ {
+		s.Clear()
+		s.AddAll(randValues(r)...)
+		if s.IsEmpty() {
+			continue
+		}
+
+		if got, want := s.WordCount(), s.Max()/wordSize+1; got != want {
+			t.Fatalf("%s.WordCount: got %d, want %d", &s, got, want)
+		}
+		if got, want := s.CapBits(), intset.WordsCap(&s)*wordSize; got != want {
+			t.Fatalf("%s.CapBits: got %d, want %d", &s, got, want)
+		}
+	}
+
+	// Trailing zero words are not counted.
+	intset.SetWords(&s, []uint{1, 0, 0})
+	if n := s.WordCount(); n != 1 {
+		t.Errorf("WordCount with trailing zero words: got %d, want 1", n)
+	}
+
+	s.Clear()
+	s.Grow(10 * wordSize)
+	if n := s.WordCount(); n != 0 {
+		t.Errorf("WordCount after Grow: got %d, want 0", n)
+	}
+	if n := s.CapBits(); n < 11*wordSize {
+		t.Errorf("CapBits after Grow: got %d, want at least %d", n, 11*wordSize)
+	}
+}
+
+func TestAddRange(t *testing.T) {
+	t.Parallel()
+
+	testcases := []struct {
+		s      []int
+		lo, hi int
+	}{
+		{nil, 0, 0},
+		{nil, 5, 3},
+		{nil, 0, 1},
+		{nil, 3, 10},
+		{[]int{1, 144}, 2, 64},
+		{[]int{1, 144}, 0, 64},
+		{[]int{1, 144}, 63, 65},
+		{[]int{1, 144}, 60, 300},
+		{[]int{1, 1000}, 64, 128},
+		{[]int{1, 1000}, 100, 900},
+	}
+
+	for _, tc := range testcases {
+		var got, want intset.IntSet[int]
+		got.AddAll(tc.s...)
+		want.AddAll(tc.s...)
+
+		got.AddRange(tc.lo, tc.hi)
+		for x := tc.lo; x < tc.hi; x++ {
+			want.Add(x)
+		}
+
+		if !got.Equals(&want) {
+			t.Errorf("AddRange(%d, %d): got %s, want %s", tc.lo, tc.hi, &got, &want)
+		}
+	}
+
+}
+
+func TestCompare(t *testing.T) {
+	t.Parallel()
+
+	testcases := []struct {
+		s, t []int
+		want int
+	}{
+		{nil, nil, 0},
+		{nil, []int{0}, -1},
+		{[]int{1, 9, 144}, []int{1, 9, 144}, 0},
+		{[]int{1, 9}, []int{1, 9, 144}, -1},
+		{[]int{1, 9, 144}, []int{1, 9}, +1},
+		{[]int{1, 9, 144}, []int{1, 10}, -1},
+		{[]int{1, 10}, []int{1, 9, 144}, +1},
+		{[]int{63}, []int{63, 64}, -1},
+		{[]int{63, 1000}, []int{64}, -1},
+		{[]int{2}, []int{1, 1000}, +1},
+	}
+
+	for _, tc := range testcases {
+		var s1, s2 intset.IntSet[int]
+		s1.AddAll(tc.s...)
+		s2.AddAll(tc.t...)
+
+		if got := s1.Compare(&s2); got != tc.want {
+			t.Errorf("%s.Compare(%s): got %d, want %d", &s1, &s2, got, tc.want)
+		}
+	}
+
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 100; i++ {
+		// Small sets over a small range make shared prefixes likely.
+		var s1, s2 intset.IntSet[int]
+		for j := r.Intn(4); j > 0; j-- {
+			s1.Add(r.Intn(200))
+		}
+		for j := r.Intn(4); j > 0; j-- {
+			s2.Add(r.Intn(200))
+		}
+
+		want := slices.Compare(s1.Elems(), s2.Elems())
+		if got := s1.Compare(&s2); got != want {
+			t.Fatalf("%s.Compare(%s): got %d, want %d", &s1, &s2, got, want)
+		}
+	}
+}
+
+func TestCount(t *testing.T) {
+	t.Parallel()
+
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 200; i++ {
+		var s intset.IntSet[int]
+		s.AddAll(randValues(r)...)
+
+		lo, hi := r.Intn(1<<12), r.Intn(1<<13)
+		if i%10 == 0 {
+			// Within a single word.
+			hi = lo + r.Intn(8)
+		}
+
+		want := 0
+		for _, x := range s.Elems() {
+			if lo <= x && x < hi {
+				want++
+			}
+		}
+
+		if got := s.Count(lo, hi); got != want {
+			t.Fatalf("%s.Count(%d, %d): got %d, want %d", &s, lo, hi, got, want)
+		}
+	}
+
+	var s intset.IntSet[int]
+	s.AddAll(1, 3, 5, 144)
+	for _, tc := range []struct{ lo, hi, want int }{
+		{0, 0, 0},
+		{5, 1, 0},
+		{1, 2, 1},
+		{2, 5, 1},
+		{1, 6, 3},
+		{6, 144, 0},
+		{0, intset.MaxInt, 4},
+	} {
+		if got := s.Count(tc.lo, tc.hi); got != tc.want {
+			t.Errorf("%s.Count(%d, %d): got %d, want %d", &s, tc.lo, tc.hi, got, tc.want)
+		}
+	}
+}
+
+func TestForEachRange(t *testing.T) {
+	t.Parallel()
+
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 200; i++ {
+		var s intset.IntSet[int]
+		s.AddAll(randValues(r)...)
+
+		lo, hi := r.Intn(1<<12), r.Intn(1<<13)
+		if i%10 == 0 {
+			// Within a single word.
+			hi = lo + r.Intn(8)
+		}
+
+		var want []int
+		for _, x := range s.Elems() {
+			if lo <= x && x < hi {
+				want = append(want, x)
+			}
+		}
+
+		var got []int
+		s.ForEachRange(lo, hi, func(x int) bool {
+			got = append(got, x)
+			return true
+		})
+		if !cmp.Equal(want, got) {
+			t.Fatalf("%s.ForEachRange(%d, %d): %s", &s, lo, hi, cmp.Diff(want, got))
+		}
+
+		// Stop after the first two elements.
+		got = got[:0]
+		s.ForEachRange(lo, hi, func(x int) bool {
+			got = append(got, x)
+			return len(got) < 2
+		})
+		if len(want) > 2 {
+			want = want[:2]
+		}
+		if !cmp.Equal(want, got, cmpopts.EquateEmpty()) {
+			t.Fatalf("%s.ForEachRange(%d, %d) stopping early: %s", &s, lo, hi, cmp.Diff(want, got, cmpopts.EquateEmpty()))
+		}
+	}
+
+	var empty intset.IntSet[int]
+	empty.ForEachRange(0, 100, func(x int) bool {
+		t.Errorf("ForEachRange on empty set: called with %d", x)
+		return true
+	})
+}
+
+func TestClearBelowAndAbove(t *testing.T) {
+	t.Parallel()
+
+	wordSize := 32 << (^uint(0) >> 63)
+
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 200; i++ {
+		var s intset.IntSet[int]
+		s.AddAll(randValues(r)...)
+		x := r.Intn(1 << 13)
+
+		var below, above []int
+		for _, y := range s.Elems() {
+			if y >= x {
+				below = append(below, y)
+			} else {
+				above = append(above, y)
+			}
+		}
+
+		b := s.Copy()
+		b.ClearBelow(x)
+		if got := b.Elems(); !cmp.Equal(below, got, cmpopts.EquateEmpty()) {
+			t.Fatalf("%s.ClearBelow(%d): %s", &s, x, cmp.Diff(below, got, cmpopts.EquateEmpty()))
+		}
+		if len(below) > 0 && b.Min() != below[0] {
+			t.Fatalf("%s.ClearBelow(%d): Min got %d, want %d", &s, x, b.Min(), below[0])
+		}
+		if len(below) == 0 && len(b.DebugWords()) != 0 {
+			t.Fatalf("%s.ClearBelow(%d): empty set has %d words", &s, x, len(b.DebugWords()))
+		}
+
+		a := s.Copy()
+		a.ClearAbove(x)
+		if got := a.Elems(); !cmp.Equal(above, got, cmpopts.EquateEmpty()) {
+			t.Fatalf("%s.ClearAbove(%d): %s", &s, x, cmp.Diff(above, got, cmpopts.EquateEmpty()))
+		}
+		if len(above) > 0 && a.Max() != above[len(above)-1] {
+			t.Fatalf("%s.ClearAbove(%d): Max got %d, want %d", &s, x, a.Max(), above[len(above)-1])
+		}
+		if a.Len() > 0 && len(a.DebugWords()) != a.Max()/wordSize+1 {
+			t.Fatalf("%s.ClearAbove(%d): untrimmed words", &s, x)
+		}
+	}
+
+	// Removed elements do not linger in the spare capacity.
+	words := []uint{1, 1, 1, 1}
+	var s intset.IntSet[int]
+	intset.SetWords(&s, words)
+	s.ClearAbove(wordSize)
+	if !cmp.Equal([]uint{1, 0, 0, 0}, words) {
+		t.Errorf("ClearAbove left removed words %v", words)
+	}
+
+	intset.SetWords(&s, words)
+	s.ClearBelow(4 * wordSize)
+	if !cmp.Equal([]uint{0, 0, 0, 0}, words) {
+		t.Errorf("ClearBelow left removed words %v", words)
+	}
+}
+
+func TestIntersectsRange(t *testing.T) {
+	t.Parallel()
+
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 500; i++ {
+		var s intset.IntSet[int]
+		for j := r.Intn(10); j > 0; j-- {
+			s.Add(r.Intn(1 << 12))
+		}
+
+		lo, hi := r.Intn(1<<12), r.Intn(1<<13)
+		if i%5 == 0 {
+			// Within a single word.
+			hi = lo + r.Intn(8)
+		}
+
+		if got, want := s.IntersectsRange(lo, hi), s.Count(lo, hi) > 0; got != want {
+			t.Fatalf("%s.IntersectsRange(%d, %d): got %t, want %t", &s, lo, hi, got, want)
+		}
+	}
+
+	var s intset.IntSet[int]
+	s.AddAll(3, 5)
+	for _, tc := range []struct {
+		lo, hi int
+		want   bool
+	}{
+		{0, 3, false},
+		{0, 4, true},
+		{4, 5, false},
+		{4, 6, true},
+		{6, 1 << 20, false},
+		{5, 5, false},
+		{5, 3, false},
+	} {
+		if got := s.IntersectsRange(tc.lo, tc.hi); got != tc.want {
+			t.Errorf("%s.IntersectsRange(%d, %d): got %t, want %t", &s, tc.lo, tc.hi, got, tc.want)
+		}
+	}
+}
+
+func TestRemoveRange(t *testing.T) {
+	t.Parallel()
+
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 200; i++ {
+		var s intset.IntSet[int]
+		s.AddAll(randValues(r)...)
+
+		lo, hi := r.Intn(1<<12), r.Intn(1<<13)
+		if i%10 == 0 {
+			// Within a single word.
+			hi = lo + r.Intn(8)
+		}
+
+		var rng intset.IntSet[int]
+		rng.AddRange(lo, hi)
+		want := s.Copy()
+		want.DifferenceWith(&rng)
+
+		n := s.Len()
+		present := 0
+		for _, x := range s.Elems() {
+			if lo <= x && x < hi {
+				present++
+			}
+		}
+
+		s.RemoveRange(lo, hi)
+		if !s.Equals(want) {
+			t.Fatalf("RemoveRange(%d, %d): got %s, want %s", lo, hi, &s, want)
+		}
+
+		if got := s.Len(); got != n-present {
+			t.Fatalf("RemoveRange(%d, %d): Len got %d, want %d", lo, hi, got, n-present)
+		}
+	}
+
+	var s intset.IntSet[int]
+	s.AddAll(1, 9, 144)
+	s.RemoveRange(100, 1<<20)
+	if want, got := "{1 9}", s.String(); !cmp.Equal(want, got) {
+		t.Error(cmp.Diff(want, got))
+	}
+	if n := len(s.DebugWords()); n > 3 {
+		t.Errorf("RemoveRange beyond Max grew the set to %d words", n)
+	}
+}
+
+func TestBits(t *testing.T) {
+	t.Parallel()
+
+	for _, x := range []uint64{0, 1, 1<<63 | 1, 0xdeadbeef, 1<<40 | 1<<31 | 1<<32, ^uint64(0)} {
+		s := intset.FromBits[int](x)
+		for i := 0; i < 64; i++ {
+			if want := x&(1<<i) != 0; s.Has(i) != want {
+				t.Errorf("FromBits(%#x).Has(%d): got %t, want %t", x, i, !want, want)
+			}
+		}
+
+		got, ok := s.ToBits()
+		if !ok || got != x {
+			t.Errorf("FromBits(%#x).ToBits(): got %#x, %t, want %#x, true", x, got, ok, x)
+		}
+	}
+
+	var s intset.IntSet[int]
+	s.AddAll(1, 9, 64)
+	if got, ok := s.ToBits(); ok || got != 1<<1|1<<9 {
+		t.Errorf("%s.ToBits(): got %#x, %t, want %#x, false", &s, got, ok, 1<<1|1<<9)
+	}
+}
+
+func TestUnion(t *testing.T) {
+	t.Parallel()
+
+	f := func(calls []intSetCall) []setResult {
+		return applyIntSetCalls(func(s, t *intset.IntSet[int]) (any, bool) {
+			u := s.Copy()
+			u.UnionWith(t)
+			return [3]string{s.String(), t.String(), u.String()}, true
+		}, calls)
+	}
+
+	g := func(calls []intSetCall) []setResult {
+		return applyIntSetCalls(func(s, t *intset.IntSet[int]) (any, bool) {
+			u := s.Union(t)
+			return [3]string{s.String(), t.String(), u.String()}, true
+		}, calls)
+	}
+
+	if err := quick.CheckEqual(f, g, nil); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestIntersection(t *testing.T) {
+	t.Parallel()
+
+	f := func(calls []intSetCall) []setResult {
+		return applyIntSetCalls(func(s, t *intset.IntSet[int]) (any, bool) {
+			u := s.Copy()
+			u.IntersectWith(t)
+			return [3]string{s.String(), t.String(), u.String()}, true
+		}, calls)
+	}
+
+	g := func(calls []intSetCall) []setResult {
+		return applyIntSetCalls(func(s, t *intset.IntSet[int]) (any, bool) {
+			u := s.Intersection(t)
+			return [3]string{s.String(), t.String(), u.String()}, true
+		}, calls)
+	}
+
+	if err := quick.CheckEqual(f, g, nil); err != nil {
+		t.Error(err)
+	}
+
+	var s1, s2 intset.IntSet[int]
+	s1.AddAll(1, 9, 1000)
+	s2.AddAll(9, 1000, 2000)
+	s2.Remove(1000)
+
+	u := s1.Intersection(&s2)
+	if want, got := "{9}", u.String(); !cmp.Equal(want, got) {
+		t.Error(cmp.Diff(want, got))
+	}
+	if n := len(u.DebugWords()); n != 1 {
+		t.Errorf("Intersection: got %d words, want 1", n)
+	}
+}
+
+func TestExactlyOne(t *testing.T) {
+	t.Parallel()
+
+	f := func(calls []intSetCall) []setResult {
+		return applyIntSetCalls(func(s, t *intset.IntSet[int]) (any, bool) {
+			u := s.Copy()
+			u.SymmetricDifference(t)
+			return [3]string{s.String(), t.String(), u.String()}, u.Len() > 0
+		}, calls)
+	}
+
+	g := func(calls []intSetCall) []setResult {
+		return applyIntSetCalls(func(s, t *intset.IntSet[int]) (any, bool) {
+			u, n := s.ExactlyOne(t)
+			if n != u.Len() {
+				return nil, false
+			}
+			return [3]string{s.String(), t.String(), u.String()}, n > 0
+		}, calls)
+	}
+
+	if err := quick.CheckEqual(f, g, nil); err != nil {
+		t.Error(err)
+	}
+
+	var s1, s2 intset.IntSet[int]
+	s1.AddAll(1, 9, 144)
+	s2.AddAll(9, 42)
+	u, n := s1.ExactlyOne(&s2)
+	if want, got := "{1 42 144}", u.String(); !cmp.Equal(want, got) || n != 3 {
+		t.Errorf("ExactlyOne: got %s, %d, want %s, 3", got, n, want)
+	}
+}
+
+func TestEachLabel(t *testing.T) {
+	t.Parallel()
+
+	var keys KeySet
+	keys.AddAll(Crystal, Copper)
+
+	var got []string
+	keys.EachLabel(func(k Key, label string) {
+		got = append(got, fmt.Sprintf("%d:%s", k, label))
+	})
+
+	if want := []string{"0:copper", "2:crystal"}; !cmp.Equal(want, got) {
+		t.Error(cmp.Diff(want, got))
+	}
+
+	var s intset.IntSet[int]
+	s.AddAll(144, 9)
+
+	got = got[:0]
+	s.EachLabel(func(_ int, label string) {
+		got = append(got, label)
+	})
+
+	if want := []string{"9", "144"}; !cmp.Equal(want, got) {
+		t.Error(cmp.Diff(want, got))
+	}
+}
+
+func TestDifference(t *testing.T) {
+	t.Parallel()
+
+	f := func(calls []intSetCall) []setResult {
+		return applyIntSetCalls(func(s, t *intset.IntSet[int]) (any, bool) {
+			u := s.Copy()
+			u.DifferenceWith(t)
+			return [3]string{s.String(), t.String(), u.String()}, true
+		}, calls)
+	}
+
+	g := func(calls []intSetCall) []setResult {
+		return applyIntSetCalls(func(s, t *intset.IntSet[int]) (any, bool) {
+			u := s.Difference(t)
+			return [3]string{s.String(), t.String(), u.String()}, true
+		}, calls)
+	}
+
+	if err := quick.CheckEqual(f, g, nil); err != nil {
+		t.Error(err)
+	}
+
+	s := intset.FromSlice([]int{1, 200})
+	u := s.Difference(intset.FromSlice([]int{200}))
+	if n := u.WordCount(); n != 1 {
+		t.Errorf("%s.Difference: got %d words, want 1", s, n)
+	}
+}
+
+func TestSymmetricDiff(t *testing.T) {
+	t.Parallel()
+
+	f := func(calls []intSetCall) []setResult {
+		return applyIntSetCalls(func(s, t *intset.IntSet[int]) (any, bool) {
+			u := s.Copy()
+			u.SymmetricDifference(t)
+			return [3]string{s.String(), t.String(), u.String()}, true
+		}, calls)
+	}
+
+	g := func(calls []intSetCall) []setResult {
+		return applyIntSetCalls(func(s, t *intset.IntSet[int]) (any, bool) {
+			u := s.SymmetricDiff(t)
+			return [3]string{s.String(), t.String(), u.String()}, true
+		}, calls)
+	}
+
+	if err := quick.CheckEqual(f, g, nil); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestIntersectionCardinality(t *testing.T) {
+	t.Parallel()
+
+	f := func(calls []intSetCall) []setResult {
+		return applyIntSetCalls(func(s, t *intset.IntSet[int]) (any, bool) {
+			u := s.Copy()
+			u.IntersectWith(t)
+			return u.Len(), true
+		}, calls)
+	}
+
+	g := func(calls []intSetCall) []setResult {
+		return applyIntSetCalls(func(s, t *intset.IntSet[int]) (any, bool) {
+			return s.IntersectionCardinality(t), true
+		}, calls)
+	}
+
+	if err := quick.CheckEqual(f, g, nil); err != nil {
+		t.Error(err)
+	}
+
+	var s1, s2 intset.IntSet[int]
+	s1.AddAll(1, 9, 144)
+	s2.AddAll(2, 42, 1000)
+
+	if n := s1.IntersectionCardinality(&s2); n != 0 {
+		t.Errorf("disjoint IntersectionCardinality: got %d, want 0", n)
+	}
+	if n := s1.IntersectionCardinality(&s1); n != 3 {
+		t.Errorf("identical IntersectionCardinality: got %d, want 3", n)
+	}
+}
+
+func TestBlockFillRatios(t *testing.T) {
+	t.Parallel()
+
+	blockFillRatios := func(s *intset.IntSet[int], size int) []float64 {
+		if s.IsEmpty() {
+			return nil
+		}
+
+		counts := make([]int, s.Max()/size+1)
+		for _, x := range s.Elems() {
+			counts[x/size]++
+		}
+
+		ratios := make([]float64, len(counts))
+		for i, n := range counts {
+			ratios[i] = float64(n) / float64(size)
+		}
+		return ratios
+	}
+
+	layouts := [][]int{
+		nil,
+		{0},
+		{1, 9, 144},
+		{0, 1, 2, 3, 62, 63, 64, 65, 1000},
+	}
+
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 10; i++ {
+		layouts = append(layouts, randValues(r))
+	}
+
+	for _, xs := range layouts {
+		var s intset.IntSet[int]
+		s.AddAll(xs...)
+
+		for _, size := range []int{1, 3, 10, 64, 100, 1000} {
+			want := blockFillRatios(&s, size)
+			if got := s.BlockFillRatios(size); !cmp.Equal(want, got) {
+				t.Errorf("%s.BlockFillRatios(%d): %s", &s, size, cmp.Diff(want, got))
+			}
+		}
+	}
+
+	defer func() {
+		if r := recover(); r == nil {
+			t.Error("BlockFillRatios(0): did not panic")
+		}
+	}()
+
+	var s intset.IntSet[int]
+	s.BlockFillRatios(0)
+}
+
+func TestUnionCardinality(t *testing.T) {
+	t.Parallel()
+
+	f := func(calls []intSetCall) []setResult {
+		return applyIntSetCalls(func(s, t *intset.IntSet[int]) (any, bool) {
+			u := s.Copy()
+			u.UnionWith(t)
+			return u.Len(), true
+		}, calls)
+	}
+
+	g := func(calls []intSetCall) []setResult {
+		return applyIntSetCalls(func(s, t *intset.IntSet[int]) (any, bool) {
+			return s.UnionCardinality(t), true
+		}, calls)
+	}
+
+	if err := quick.CheckEqual(f, g, nil); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestDifferenceCardinality(t *testing.T) {
+	t.Parallel()
+
+	f := func(calls []intSetCall) []setResult {
+		return applyIntSetCalls(func(s, t *intset.IntSet[int]) (any, bool) {
+			u := s.Copy()
+			u.DifferenceWith(t)
+			return u.Len(), true
+		}, calls)
+	}
+
+	g := func(calls []intSetCall) []setResult {
+		return applyIntSetCalls(func(s, t *intset.IntSet[int]) (any, bool) {
+			return s.DifferenceCardinality(t), true
+		}, calls)
+	}
+
+	if err := quick.CheckEqual(f, g, nil); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestJaccard(t *testing.T) {
+	t.Parallel()
+
+	testcases := []struct {
+		s, t []int
+		want float64
+	}{
+		{nil, nil, 1},
+		{[]int{1, 2}, nil, 0},
+		{nil, []int{1, 2}, 0},
+		{[]int{1, 9, 144}, []int{1, 9, 144}, 1},
+		{[]int{1, 9, 144}, []int{2, 42, 1000}, 0},
+		{[]int{1, 2, 3, 4}, []int{3, 4, 200}, 2.0 / 5},
+		{[]int{0, 100}, []int{100, 200, 300, 400}, 1.0 / 5},
+	}
+
+	for _, tc := range testcases {
+		var s1, s2 intset.IntSet[int]
+		s1.AddAll(tc.s...)
+		s2.AddAll(tc.t...)
+
+		if got := s1.Jaccard(&s2); got != tc.want {
+			t.Errorf("%s.Jaccard(%s): got %v, want %v", &s1, &s2, got, tc.want)
+		}
+	}
+}
+
+func TestRank(t *testing.T) {
+	t.Parallel()
+
+	rank := func(s *intset.IntSet[int], x int) int {
+		n := 0
+		for _, y := range s.Elems() {
+			if y <= x {
+				n++
+			}
+		}
+		return n
+	}
+
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 100; i++ {
+		var s intset.IntSet[int]
+		s.AddAll(randValues(r)...)
+
+		xs := []int{-1, 0, s.Min() - 1, s.Min(), s.Max(), s.Max() + 1, intset.MaxInt}
+		for j := 0; j < 10; j++ {
+			xs = append(xs, r.Intn(1<<13))
+		}
+
+		for _, x := range xs {
+			if want, got := rank(&s, x), s.Rank(x); got != want {
+				t.Fatalf("Rank(%d): got %d, want %d", x, got, want)
+			}
+		}
+	}
+
+	var empty intset.IntSet[int]
+	if n := empty.Rank(100); n != 0 {
+		t.Errorf("{}.Rank(100): got %d, want 0", n)
+	}
+}
+
+func TestSelect(t *testing.T) {
+	t.Parallel()
+
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 100; i++ {
+		var s intset.IntSet[int]
+		s.AddAll(randValues(r)...)
+
+		elems := s.Elems()
+		for k, want := range elems {
+			if got, ok := s.Select(k); !ok || got != want {
+				t.Fatalf("Select(%d): got %d, %t, want %d, true", k, got, ok, want)
+			}
+		}
+
+		for _, k := range []int{-1, len(elems), len(elems) + 1} {
+			if got, ok := s.Select(k); ok {
+				t.Fatalf("Select(%d) of %d elements: got %d, true, want false", k, len(elems), got)
+			}
+		}
+	}
+
+	var empty intset.IntSet[int]
+	if got, ok := empty.Select(0); ok {
+		t.Errorf("{}.Select(0): got %d, true, want false", got)
+	}
+}
+
+func TestUpperBound(t *testing.T) {
+	t.Parallel()
+
+	var s intset.IntSet[int]
+	s.AddAll(0, 1, 9, 63, 64, 144)
+
+	testcases := []struct {
+		x, want int
+	}{
+		{-5, 0},
+		{0, 1},
+		{1, 9},    // present
+		{2, 9},    // absent
+		{62, 63},  // word boundary
+		{63, 64},  // word boundary
+		{64, 144}, // present
+		{144, intset.MaxInt},
+		{145, intset.MaxInt},
+		{intset.MaxInt, intset.MaxInt},
+	}
+
+	for _, tc := range testcases {
+		if got := s.UpperBound(tc.x); got != tc.want {
+			t.Errorf("%s.UpperBound(%d): got %d, want %d", &s, tc.x, got, tc.want)
+		}
+	}
+}
+
+func TestTakeMax(t *testing.T) {
+	t.Parallel()
+
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 100; i++ {
+		var s intset.IntSet[int]
+		s.AddAll(randValues(r)...)
+
+		n := s.Len()
+		prev := intset.MaxInt
+		for j := 0; j < n; j++ {
+			var x int
+			if !s.TakeMax(&x) {
+				t.Fatalf("TakeMax #%d of %d: returned false", j, n)
+			}
+
+			if x >= prev {
+				t.Fatalf("TakeMax #%d: got %d after %d", j, x, prev)
+			}
+			prev = x
+		}
+
+		var x int
+		if s.TakeMax(&x) {
+			t.Fatalf("TakeMax on empty set returned true with %d", x)
+		}
+	}
+}
+
+func TestNextPrev(t *testing.T) {
+	t.Parallel()
+
+	r := rand.New(rand.NewSource(1))
+	sets := [][]int{nil, {0}, {63, 64}}
+	for i := 0; i < 100; i++ {
+		sets = append(sets, randValues(r))
+	}
+
+	for _, xs := range sets {
+		var s intset.IntSet[int]
+		s.AddAll(xs...)
+
+		var got []int
+		for x, ok := s.Next(s.Min() - 1); ok; x, ok = s.Next(x) {
+			got = append(got, x)
+		}
+
+		want := s.Elems()
+		if !cmp.Equal(want, got, cmpopts.EquateEmpty()) {
+			t.Fatalf("Next walk: %s", cmp.Diff(want, got, cmpopts.EquateEmpty()))
+		}
+
+		got = got[:0]
+		for x, ok := s.Prev(s.Max() + 1); ok; x, ok = s.Prev(x) {
+			got = append(got, x)
+		}
+
+		for i, j := 0, len(want)-1; i < j; i, j = i+1, j-1 {
+			want[i], want[j] = want[j], want[i]
+		}
+		if !cmp.Equal(want, got, cmpopts.EquateEmpty()) {
+			t.Fatalf("Prev walk: %s", cmp.Diff(want, got, cmpopts.EquateEmpty()))
+		}
+	}
+
+	var s intset.IntSet[int]
+	s.AddAll(0, 9, 144)
+
+	if x, ok := s.Next(intset.MaxInt); ok {
+		t.Errorf("Next(MaxInt): got %d, true, want false", x)
+	}
+	if x, ok := s.Next(-10); !ok || x != 0 {
+		t.Errorf("Next(-10): got %d, %t, want 0, true", x, ok)
+	}
+	if x, ok := s.Prev(0); ok {
+		t.Errorf("Prev(0): got %d, true, want false", x)
+	}
+	if x, ok := s.Prev(intset.MaxInt); !ok || x != 144 {
+		t.Errorf("Prev(MaxInt): got %d, %t, want 144, true", x, ok)
+	}
+}
+
+func TestTrim(t *testing.T) {
+	t.Parallel()
+
+	var s, high intset.IntSet[int]
+	s.AddAll(1, 9)
+	n := len(s.DebugWords())
+	high.Add(1000)
+
+	for _, op := range []struct {
+		name string
+		f    func()
+	}{
+		{"Remove", func() { s.Remove(1000) }},
+		{"RemoveRange", func() { s.RemoveRange(500, 2000) }},
+		{"IntersectWith", func() {
+			var t intset.IntSet[int]
+			t.AddAll(1, 9)
+			s.IntersectWith(&t)
+		}},
+		{"DifferenceWith", func() { s.DifferenceWith(&high) }},
+	} {
+		s.Add(1000)
+		op.f()
+
+		if want, got := "{1 9}", s.String(); got != want {
+			t.Errorf("%s: got %s, want %s", op.name, got, want)
+		}
+		if got := len(s.DebugWords()); got != n {
+			t.Errorf("%s: got %d words, want %d", op.name, got, n)
+		}
+	}
+}
+
+func TestEqualsAfterOps(t *testing.T) {
+	t.Parallel()
+
+	// Two sets built by different operation histories
+	// are Equals if they have the same elements.
+	f := func(calls []setCall) bool {
+		var s IntSet
+		for _, c := range calls {
+			c.apply(&s)
+		}
+
+		var t intset.IntSet[int]
+		t.AddAll(s.Elems()...)
+
+		return s.IntSet.Equals(&t) && t.Equals(&s.IntSet)
+	}
+
+	if err := quick.Check(f, nil); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestShrink(t *testing.T) {
+	t.Parallel()
+
+	var s intset.IntSet[int]
+	for x := 0; x < 10000; x += 7 {
+		s.Add(x)
+	}
+	before := intset.WordsCap(&s)
+
+	s.RemoveRange(100, 10000)
+	if got := intset.WordsCap(&s); got != before {
+		t.Fatalf("RemoveRange changed capacity from %d to %d", before, got)
+	}
+
+	want := s.String()
+	s.Shrink()
+
+	if got := s.String(); got != want {
+		t.Errorf("Shrink: got %s, want %s", got, want)
+	}
+	if got := intset.WordsCap(&s); got >= before/4 {
+		t.Errorf("Shrink: got capacity %d, want less than %d", got, before/4)
+	}
+
+	s.Clear()
+	s.Shrink()
+	if got := intset.WordsCap(&s); got != 0 {
+		t.Errorf("Shrink of empty set: got capacity %d, want 0", got)
+	}
+}
+
+func TestGrow(t *testing.T) {
+	t.Parallel()
+
+	var s intset.IntSet[int]
+	s.AddAll(1, 9, 144)
+
+	s.Grow(10000)
+	if want, got := "{1 9 144}", s.String(); got != want {
+		t.Errorf("Grow changed elements: got %s, want %s", got, want)
+	}
+
+	c := intset.WordsCap(&s)
+	for x := 0; x <= 10000; x += 3 {
+		s.Add(x)
+	}
+	if got := intset.WordsCap(&s); got != c {
+		t.Errorf("Add after Grow(10000) reallocated: capacity %d, want %d", got, c)
+	}
+
+	defer func() {
+		if r := recover(); r == nil {
+			t.Error("Grow(-1): did not panic")
+		}
+	}()
+	s.Grow(-1)
+}
+
+func TestNewWithCapacity(t *testing.T) {
+	t.Parallel()
+
+	const maxValue = 5000
+
+	s := intset.NewWithCapacity(maxValue)
+	if n := s.Len(); n != 0 || !s.IsEmpty() {
+		t.Errorf("NewWithCapacity: got Len %d, IsEmpty %t, want 0, true", n, s.IsEmpty())
+	}
+
+	c := intset.WordsCap(s)
+	if c == 0 {
+		t.Fatal("NewWithCapacity: got zero capacity")
+	}
+
+	for x := maxValue; x >= 0; x -= 7 {
+		s.Add(x)
+	}
+	s.Add(maxValue - 1)
+
+	if got := intset.WordsCap(s); got != c {
+		t.Errorf("Add up to maxValue reallocated: capacity %d, want %d", got, c)
+	}
+}
+
+func TestReset(t *testing.T) {
+	t.Parallel()
+
+	var s intset.IntSet[int]
+	s.AddAll(1, 9, 144, 1000)
+	c := intset.WordsCap(&s)
+
+	s.Reset()
+	if n := s.Len(); n != 0 || !s.IsEmpty() {
+		t.Errorf("Reset: got Len %d, IsEmpty %t, want 0, true", n, s.IsEmpty())
+	}
+	if got := intset.WordsCap(&s); got != c {
+		t.Errorf("Reset: got capacity %d, want %d", got, c)
+	}
+
+	// Stale words must not reappear when the set grows again.
+	s.Add(2000)
+	if want, got := "{2000}", s.String(); got != want {
+		t.Errorf("Add after Reset: got %s, want %s", got, want)
+	}
+}
+
+func TestComplement(t *testing.T) {
+	t.Parallel()
+
+	r := rand.New(rand.NewSource(1))
+	sets := [][]int{nil, {0}, {1, 9, 144}}
+	for i := 0; i < 50; i++ {
+		sets = append(sets, randValues(r))
+	}
+
+	for _, xs := range sets {
+		var s intset.IntSet[int]
+		s.AddAll(xs...)
+
+		for _, n := range []int{-1, 0, 1, 63, 64, 65, 145, 1000, 1 << 13} {
+			want := new(intset.IntSet[int])
+			want.AddRange(0, n)
+			want.DifferenceWith(&s)
+
+			if got := s.Complement(n); !got.Equals(want) {
+				t.Fatalf("%s.Complement(%d): got %s, want %s", &s, n, got, want)
+			}
+		}
+	}
+}
+
+func TestContainsAllAny(t *testing.T) {
+	t.Parallel()
+
+	var s intset.IntSet[int]
+	s.AddAll(1, 9, 144)
+
+	testcases := []struct {
+		xs       []int
+		all, any bool
+	}{
+		{nil, true, false},
+		{[]int{9}, true, true},
+		{[]int{1, 144, 9}, true, true},
+		{[]int{1, 2}, false, true},
+		{[]int{2, 1000}, false, false},
+	}
+
+	for _, tc := range testcases {
+		if got := s.ContainsAll(tc.xs...); got != tc.all {
+			t.Errorf("%s.ContainsAll(%v): got %t, want %t", &s, tc.xs, got, tc.all)
+		}
+		if got := s.ContainsAny(tc.xs...); got != tc.any {
+			t.Errorf("%s.ContainsAny(%v): got %t, want %t", &s, tc.xs, got, tc.any)
+		}
+	}
+}
+
+func TestRemoveAll(t *testing.T) {
+	t.Parallel()
+
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 100; i++ {
+		var s intset.IntSet[int]
+		s.AddAll(randValues(r)...)
+
+		xs := randValues(r)
+		xs = append(xs, xs[0], s.Max(), s.Max()+1) // duplicates, high and absent values
+
+		want := s.Copy()
+		for _, x := range xs {
+			want.Remove(x)
+		}
+
+		s.RemoveAll(xs...)
+		if !s.Equals(want) {
+			t.Fatalf("RemoveAll: got %s, want %s", &s, want)
+		}
+		if got, want := len(s.DebugWords()), len(want.DebugWords()); got != want {
+			t.Fatalf("RemoveAll: got %d words, want %d", got, want)
+		}
+	}
+}
+
+func TestToggle(t *testing.T) {
+	t.Parallel()
+
+	var s intset.IntSet[int]
+	s.AddAll(1, 9, 144)
+	want := s.String()
+
+	for _, x := range []int{1, 2, 144, 1000} {
+		had := s.Has(x)
+
+		if got := s.Toggle(x); got != !had || s.Has(x) != got {
+			t.Errorf("Toggle(%d): got %t, Has %t, want %t", x, got, s.Has(x), !had)
+		}
+
+		if got := s.Toggle(x); got != had || s.Has(x) != got {
+			t.Errorf("second Toggle(%d): got %t, Has %t, want %t", x, got, s.Has(x), had)
+		}
+
+		if got := s.String(); got != want {
+			t.Errorf("double Toggle(%d): got %s, want %s", x, got, want)
+		}
+	}
+}
+
+func TestToggleRange(t *testing.T) {
+	t.Parallel()
+
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 200; i++ {
+		var s intset.IntSet[int]
+		s.AddAll(randValues(r)...)
+		orig := s.Copy()
+
+		lo, hi := r.Intn(1<<12), r.Intn(1<<13)
+		if i%10 == 0 {
+			// Within a single word.
+			hi = lo + r.Intn(8)
+		}
+
+		var rng intset.IntSet[int]
+		rng.AddRange(lo, hi)
+		want := s.Copy()
+		want.SymmetricDifference(&rng)
+
+		s.ToggleRange(lo, hi)
+		if !s.Equals(want) {
+			t.Fatalf("ToggleRange(%d, %d): got %s, want %s", lo, hi, &s, want)
+		}
+
+		s.ToggleRange(lo, hi)
+		if !s.Equals(orig) {
+			t.Fatalf("double ToggleRange(%d, %d): got %s, want %s", lo, hi, &s, orig)
+		}
+	}
+}
+
+func TestEqualsMatchesMapSet(t *testing.T) {
+	t.Parallel()
+
+	// Small element values make equal sets likely.
+	f := func(xs, ys []uint8, same bool) bool {
+		var s1, s2 IntSet
+		var m1, m2 MapSet
+		for _, x := range xs {
+			s1.Add(int(x))
+			m1.Add(int(x))
+		}
+
+		if same {
+			ys = xs
+		}
+		for _, y := range ys {
+			s2.Add(int(y))
+			m2.Add(int(y))
+		}
+
+		return s1.Equals(&s2) == m1.Equals(&m2) && s2.Equals(&s1) == m2.Equals(&m1)
+	}
+
+	if err := quick.Check(f, nil); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestProperSubsetOfMatchesMapSet(t *testing.T) {
+	t.Parallel()
+
+	// Small element values make subsets and equal sets likely.
+	f := func(xs, ys []uint8, superset bool) bool {
+		var s1, s2 intset.IntSet[int]
+		var m1, m2 MapSet
+		for _, x := range xs {
+			s1.Add(int(x))
+			m1.Add(int(x))
+		}
+
+		if superset {
+			ys = append(ys, xs...)
+		}
+		for _, y := range ys {
+			s2.Add(int(y))
+			m2.Add(int(y))
+		}
+
+		want := m1.SubsetOf(&m2) && !m1.Equals(&m2)
+		return s1.ProperSubsetOf(&s2) == want && s2.ProperSupersetOf(&s1) == want
+	}
+
+	if err := quick.Check(f, nil); err != nil {
+		t.Error(err)
+	}
+
+	var s1, s2, s3 intset.IntSet[int]
+	s1.AddAll(1, 9)
+	s2.AddAll(1, 9, 144)
+	s3.AddAll(1, 144)
+	for _, tc := range []struct {
+		s, t *intset.IntSet[int]
+		want bool
+	}{
+		{&s1, &s1, false},
+		{&s1, s1.Copy(), false},
+		{&s1, &s2, true},
+		{&s2, &s1, false},
+		{&s1, &s3, false},
+		{&s3, &s1, false},
+		{new(intset.IntSet[int]), &s1, true},
+		{new(intset.IntSet[int]), new(intset.IntSet[int]), false},
+	} {
+		if got := tc.s.ProperSubsetOf(tc.t); got != tc.want {
+			t.Errorf("%s.ProperSubsetOf(%s): got %t, want %t", tc.s, tc.t, got, tc.want)
+		}
+		if got := tc.t.ProperSupersetOf(tc.s); got != tc.want {
+			t.Errorf("%s.ProperSupersetOf(%s): got %t, want %t", tc.t, tc.s, got, tc.want)
+		}
+	}
+}
+
+func TestLenMatchesMapSet(t *testing.T) {
+	t.Parallel()
+
+	r := rand.New(rand.NewSource(1))
+	var s intset.IntSet[int]
+	var m MapSet
+	for i := 0; i < 2000; i++ {
+		x := r.Intn(1 << 10)
+		switch r.Intn(6) {
+		case 0, 1:
+			s.Add(x)
+			m.Add(x)
+		case 2:
+			s.Remove(x)
+			m.Remove(x)
+		case 3:
+			var t1 intset.IntSet[int]
+			t1.AddRange(x, x+r.Intn(100))
+			s.UnionWith(&t1)
+			for y := range t1.All() {
+				m.Add(y)
+			}
+		case 4:
+			hi := x + r.Intn(100)
+			s.RemoveRange(x, hi)
+			for y := x; y < hi; y++ {
+				m.Remove(y)
+			}
+		case 5:
+			if r.Intn(20) == 0 {
+				s.Clear()
+				m.Clear()
+			}
+		}
+
+		if s.Len() != m.Len() || s.Cardinality() != m.Len() {
+			t.Fatalf("step %d: Len %d, Cardinality %d, want %d", i, s.Len(), s.Cardinality(), m.Len())
+		}
+	}
+}
+
+func TestEqualsUntrimmed(t *testing.T) {
+	t.Parallel()
+
+	var s1, s2 intset.IntSet[int]
+	intset.SetWords(&s1, []uint{1 << 3, 1 << 5})
+	intset.SetWords(&s2, []uint{1 << 3, 1 << 5, 0, 0})
+
+	if !s1.Equals(&s2) || !s2.Equals(&s1) {
+		t.Errorf("%s and %s with different word counts: Equals got false", &s1, &s2)
+	}
+
+	intset.SetWords(&s2, []uint{1 << 3, 1 << 5, 0, 1})
+	if s1.Equals(&s2) || s2.Equals(&s1) {
+		t.Errorf("%s and %s: Equals got true", &s1, &s2)
+	}
+}
+
+func TestMinOKAndMaxOK(t *testing.T) {
+	t.Parallel()
+
+	var s intset.IntSet[int]
+	if x, ok := s.MinOK(); ok {
+		t.Errorf("{}.MinOK: got %d, true, want false", x)
+	}
+	if x, ok := s.MaxOK(); ok {
+		t.Errorf("{}.MaxOK: got %d, true, want false", x)
+	}
+
+	for _, x := range []int{456, 123, 789, 0} {
+		s.Add(x)
+
+		if got, ok := s.MinOK(); !ok || got != s.Min() {
+			t.Errorf("%s.MinOK: got %d, %t, want %d, true", &s, got, ok, s.Min())
+		}
+		if got, ok := s.MaxOK(); !ok || got != s.Max() {
+			t.Errorf("%s.MaxOK: got %d, %t, want %d, true", &s, got, ok, s.Max())
+		}
+	}
+}
+
+func TestNegativeValuePanics(t *testing.T) {
+	t.Parallel()
+
+	var s intset.IntSet[int]
+	s.AddAll(1, 9, 144)
+
+	for _, tc := range []struct {
+		name string
+		f    func()
+	}{
+		{"Has", func() { s.Has(-5) }},
+		{"Add", func() { s.Add(-5) }},
+		{"AddAll", func() { s.AddAll(1, -5) }},
+		{"Remove", func() { s.Remove(-5) }},
+		{"RemoveAll", func() { s.RemoveAll(-5) }},
+		{"Toggle", func() { s.Toggle(-5) }},
+		{"LowerBound", func() { s.LowerBound(-5) }},
+		{"AddRange", func() { s.AddRange(-5, 3) }},
+		{"RemoveRange", func() { s.RemoveRange(-5, 3) }},
+		{"ToggleRange", func() { s.ToggleRange(-5, 3) }},
+		{"Grow", func() { s.Grow(-5) }},
+		{"ContainsAll", func() { s.ContainsAll(-5) }},
+		{"Bit", func() { s.Bit(-5) }},
+		{"Count", func() { s.Count(-5, 3) }},
+		{"AddSlice", func() { s.AddSlice([]int{1, -5}) }},
+		{"AddIf", func() { s.AddIf(-5, false) }},
+		{"ClearBelow", func() { s.ClearBelow(-5) }},
+		{"ClearAbove", func() { s.ClearAbove(-5) }},
+		{"IntersectsRange", func() { s.IntersectsRange(-5, 3) }},
+		{"ForEachRange", func() { s.ForEachRange(-5, 3, func(int) bool { return true }) }},
+	} {
+		func() {
+			defer func() {
+				want := "intset: negative value -5"
+				if r := recover(); r != want {
+					t.Errorf("%s: got panic %v, want %q", tc.name, r, want)
+				}
+			}()
+
+			tc.f()
+		}()
+	}
+
+	if want, got := "{1 9 144}", s.String(); got != want {
+		t.Errorf("after panics: got %s, want %s", got, want)
+	}
+}
+
+type Color int8
+
+const (
+	Red Color = iota
+	Green
+	Blue
+)
+
+func (c Color) String() string {
+	switch c {
+	case Red:
+		return "red"
+	case Green:
+		return "green"
+	case Blue:
+		return "blue"
+	}
+
+	return fmt.Sprintf("Color(%d)", int8(c))
+}
+
+func TestSmallElementKinds(t *testing.T) {
+	t.Parallel()
+
+	var colors intset.IntSet[Color]
+	if !colors.Add(Blue) || !colors.Add(Red) || colors.Add(Blue) {
+		t.Fatal("Add: wrong growth reported")
+	}
+	if !colors.Has(Red) || colors.Has(Green) || !colors.Has(Blue) {
+		t.Errorf("%s: wrong membership", &colors)
+	}
+	if want, got := "{red blue}", colors.String(); !cmp.Equal(want, got) {
+		t.Error(cmp.Diff(want, got))
+	}
+
+	colors.Add(127)
+	if m := colors.Max(); m != 127 {
+		t.Errorf("Max: got %d, want 127", m)
+	}
+
+	// The sentinels are the limits of the element type.
+	var empty8 intset.IntSet[int8]
+	if m := empty8.Max(); m != math.MinInt8 {
+		t.Errorf("Max of empty IntSet[int8]: got %d, want %d", m, math.MinInt8)
+	}
+	if m := empty8.Min(); m != math.MaxInt8 {
+		t.Errorf("Min of empty IntSet[int8]: got %d, want %d", m, math.MaxInt8)
+	}
+	if b := empty8.LowerBound(3); b != math.MaxInt8 {
+		t.Errorf("LowerBound on empty IntSet[int8]: got %d, want %d", b, math.MaxInt8)
+	}
+
+	var empty16 intset.IntSet[uint16]
+	if m := empty16.Max(); m != 0 {
+		t.Errorf("Max of empty IntSet[uint16]: got %d, want 0", m)
+	}
+	if m := empty16.Min(); m != math.MaxUint16 {
+		t.Errorf("Min of empty IntSet[uint16]: got %d, want %d", m, math.MaxUint16)
+	}
+
+	var u intset.IntSet[uint64]
+	u.AddAll(1, 9, 144)
+	if want, got := "{1 9 144}", u.String(); !cmp.Equal(want, got) {
+		t.Error(cmp.Diff(want, got))
+	}
+	if b := u.UpperBound(144); b != math.MaxUint64 {
+		t.Errorf("UpperBound(144): got %d, want %d", b, uint64(math.MaxUint64))
+	}
+
+	// No element exceeds MaxInt, so these find nothing above x and
+	// everything below it rather than overflowing int.
+	for _, x := range []uint64{intset.MaxInt, intset.MaxInt + 1, math.MaxUint64} {
+		if y, ok := u.Next(x); ok {
+			t.Errorf("Next(%d): got %d, true, want false", x, y)
+		}
+		if b := u.UpperBound(x); b != math.MaxUint64 {
+			t.Errorf("UpperBound(%d): got %d, want %d", x, b, uint64(math.MaxUint64))
+		}
+		if y, ok := u.Prev(x); !ok || y != 144 {
+			t.Errorf("Prev(%d): got %d, %t, want 144, true", x, y, ok)
+		}
+		if n := u.Rank(x); n != 3 {
+			t.Errorf("Rank(%d): got %d, want 3", x, n)
+		}
+	}
+
+	var emptyU intset.IntSet[uint64]
+	if y, ok := emptyU.Prev(math.MaxUint64); ok {
+		t.Errorf("Prev(MaxUint64) on empty set: got %d, true, want false", y)
+	}
+}
+
+func TestSmallElementKindsRange(t *testing.T) {
+	t.Parallel()
+
+	var u intset.IntSet[uint64]
+	func() {
+		defer func() {
+			if r := recover(); r == nil {
+				t.Error("Add(MaxUint64): no panic")
+			}
+		}()
+		u.Add(math.MaxUint64)
+	}()
+
+	func() {
+		defer func() {
+			if r := recover(); r == nil {
+				t.Error("FromWords with an element too large for int8: no panic")
+			}
+		}()
+		words := make([]uint, 256/(32<<(^uint(0)>>63)))
+		words[len(words)-1] = 1 // the value 256 - wordSize
+		intset.FromWords[int8](words)
+	}()
+
+	var colors intset.IntSet[Color]
+	if err := colors.UnmarshalText([]byte("1,200")); err == nil {
+		t.Errorf("UnmarshalText with element 200: got %s, want error", &colors)
+	}
+	if _, err := intset.Parse[int8]("{1 128}"); err == nil {
+		t.Error("Parse with element 128: got nil error")
+	}
+
+	var wide intset.IntSet[int]
+	wide.AddAll(1, 300)
+	data, err := wide.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := colors.UnmarshalBinary(data); err == nil {
+		t.Errorf("UnmarshalBinary with element 300: got %s, want error", &colors)
+	}
+}
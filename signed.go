@@ -0,0 +1,126 @@
+package intset
+
+import "strings"
+
+// SignedIntSet is a set of integer values of either sign.
+//
+// It stores non-negative values and negative values in two IntSets,
+// the latter holding ^x = -x-1 for each negative element x, so its
+// operations have the same costs as those of IntSet.
+//
+// The zero value represents a valid empty set.
+//
+// SignedIntSet must be copied using the Copy method, not by assigning
+// a SignedIntSet value.
+type SignedIntSet[E Integer] struct {
+	neg, pos IntSet[E]
+}
+
+// half returns the half of the set s holding x, and the index of x in it.
+func (s *SignedIntSet[E]) half(x E) (*IntSet[E], E) {
+	if x < 0 {
+		return &s.neg, ^x
+	}
+
+	return &s.pos, x
+}
+
+// Has reports whether the set s contains the value x.
+func (s *SignedIntSet[E]) Has(x E) bool {
+	h, i := s.half(x)
+	return h.Has(i)
+}
+
+// Add adds the value x to the set s, and reports whether the set grew.
+func (s *SignedIntSet[E]) Add(x E) bool {
+	h, i := s.half(x)
+	return h.Add(i)
+}
+
+// AddAll adds a group of values xs to the set.
+func (s *SignedIntSet[E]) AddAll(xs ...E) {
+	for _, x := range xs {
+		s.Add(x)
+	}
+}
+
+// Remove remove x from the set s, and reports whether the set shrank.
+func (s *SignedIntSet[E]) Remove(x E) bool {
+	h, i := s.half(x)
+	return h.Remove(i)
+}
+
+// Len return the number of elements
+func (s *SignedIntSet[E]) Len() int {
+	return s.neg.Len() + s.pos.Len()
+}
+
+// IsEmpty reports whether the set s is empty.
+func (s *SignedIntSet[E]) IsEmpty() bool {
+	return s.neg.IsEmpty() && s.pos.IsEmpty()
+}
+
+// Clear remove all elements from the set s.
+func (s *SignedIntSet[E]) Clear() {
+	s.neg.Clear()
+	s.pos.Clear()
+}
+
+// Copy return a copy of the set s.
+func (s *SignedIntSet[E]) Copy() *SignedIntSet[E] {
+	return &SignedIntSet[E]{neg: *s.neg.Copy(), pos: *s.pos.Copy()}
+}
+
+// AppendTo returns the result of appending the elements of s to slice in order.
+func (s *SignedIntSet[E]) AppendTo(slice []E) []E {
+	for i := range s.neg.Backward() {
+		slice = append(slice, ^i)
+	}
+
+	return s.pos.AppendTo(slice)
+}
+
+// Elems return the elements of the set s in order.
+func (s *SignedIntSet[E]) Elems() []E {
+	return s.AppendTo(make([]E, 0, s.Len()))
+}
+
+// String returns a human-readable description of the set s.
+func (s *SignedIntSet[E]) String() string {
+	var b strings.Builder
+
+	b.WriteByte('{')
+	for _, x := range s.Elems() {
+		if b.Len() > len("{") {
+			b.WriteByte(' ')
+		}
+
+		b.WriteString(label(x))
+	}
+	b.WriteByte('}')
+
+	return b.String()
+}
+
+// Equals reports whether the sets s and t have the same elements.
+func (s *SignedIntSet[E]) Equals(t *SignedIntSet[E]) bool {
+	return s.neg.Equals(&t.neg) && s.pos.Equals(&t.pos)
+}
+
+// UnionWith sets s to the union s ∪ t.
+func (s *SignedIntSet[E]) UnionWith(t *SignedIntSet[E]) {
+	s.neg.UnionWith(&t.neg)
+	s.pos.UnionWith(&t.pos)
+}
+
+// IntersectWith sets s to the intersection s ∩ t.
+func (s *SignedIntSet[E]) IntersectWith(t *SignedIntSet[E]) {
+	s.neg.IntersectWith(&t.neg)
+	s.pos.IntersectWith(&t.pos)
+}
+
+// DifferenceWith sets s to the difference s ∖ t.
+func (s *SignedIntSet[E]) DifferenceWith(t *SignedIntSet[E]) {
+	s.neg.DifferenceWith(&t.neg)
+	s.pos.DifferenceWith(&t.pos)
+}
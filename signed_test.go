@@ -0,0 +1,100 @@
+package intset_test
+
+import (
+	"math/rand"
+	"slices"
+	"testing"
+
+	"github.com/weiwenchen2022/intset"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+func TestSignedIntSet(t *testing.T) {
+	t.Parallel()
+
+	var s intset.SignedIntSet[int]
+	if !s.Add(-1) || !s.Add(5) || !s.Add(-300) || !s.Add(0) || s.Add(-1) {
+		t.Fatal("Add: wrong growth reported")
+	}
+
+	if want, got := "{-300 -1 0 5}", s.String(); !cmp.Equal(want, got) {
+		t.Error(cmp.Diff(want, got))
+	}
+
+	for _, x := range []int{-300, -1, 0, 5} {
+		if !s.Has(x) {
+			t.Errorf("%s.Has(%d): got false", &s, x)
+		}
+	}
+	for _, x := range []int{-301, -299, -2, 1, 4} {
+		if s.Has(x) {
+			t.Errorf("%s.Has(%d): got true", &s, x)
+		}
+	}
+
+	if !s.Remove(-1) || s.Remove(-1) || s.Has(-1) {
+		t.Errorf("Remove(-1): not removed exactly once")
+	}
+
+	if n := s.Len(); n != 3 {
+		t.Errorf("%s.Len: got %d, want 3", &s, n)
+	}
+}
+
+func TestSignedIntSetElems(t *testing.T) {
+	t.Parallel()
+
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 100; i++ {
+		var s intset.SignedIntSet[int]
+		var want []int
+		for _, x := range randValues(r) {
+			x -= 1 << 11
+			if s.Add(x) {
+				want = append(want, x)
+			}
+		}
+		slices.Sort(want)
+
+		if got := s.Elems(); !cmp.Equal(want, got, cmpopts.EquateEmpty()) {
+			t.Fatal(cmp.Diff(want, got, cmpopts.EquateEmpty()))
+		}
+	}
+}
+
+func TestSignedIntSetOps(t *testing.T) {
+	t.Parallel()
+
+	var s1, s2 intset.SignedIntSet[int]
+	s1.AddAll(-9, -1, 1, 144)
+	s2.AddAll(-9, 1, 42)
+
+	u := s1.Copy()
+	u.UnionWith(&s2)
+	if want, got := "{-9 -1 1 42 144}", u.String(); !cmp.Equal(want, got) {
+		t.Error(cmp.Diff(want, got))
+	}
+
+	in := s1.Copy()
+	in.IntersectWith(&s2)
+	if want, got := "{-9 1}", in.String(); !cmp.Equal(want, got) {
+		t.Error(cmp.Diff(want, got))
+	}
+
+	d := s1.Copy()
+	d.DifferenceWith(&s2)
+	if want, got := "{-1 144}", d.String(); !cmp.Equal(want, got) {
+		t.Error(cmp.Diff(want, got))
+	}
+
+	if s1.Equals(&s2) || !s1.Equals(s1.Copy()) {
+		t.Error("Equals: wrong result")
+	}
+
+	s1.Clear()
+	if !s1.IsEmpty() {
+		t.Errorf("Clear: got %s", &s1)
+	}
+}
@@ -0,0 +1,196 @@
+package intset
+
+import (
+	"iter"
+	"sync"
+)
+
+// SyncIntSet is an IntSet that is safe for concurrent use by multiple
+// goroutines. Read-only methods hold a read lock and all others hold
+// the write lock, so each method call is atomic.
+//
+// The zero value represents a valid empty set.
+//
+// A SyncIntSet must not be copied after first use.
+type SyncIntSet[E Integer] struct {
+	mu sync.RWMutex
+	s  IntSet[E]
+}
+
+// Has reports whether the set s contains the non-negative value x.
+func (s *SyncIntSet[E]) Has(x E) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.s.Has(x)
+}
+
+// Len return the number of elements
+func (s *SyncIntSet[E]) Len() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.s.Len()
+}
+
+// IsEmpty reports whether the set s is empty.
+func (s *SyncIntSet[E]) IsEmpty() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.s.IsEmpty()
+}
+
+// Elems return the elements of the set s in order.
+func (s *SyncIntSet[E]) Elems() []E {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.s.Elems()
+}
+
+// Max returns the maximum element of the set s, or the smallest value of E
+// (MinInt for int) if s is empty.
+func (s *SyncIntSet[E]) Max() E {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.s.Max()
+}
+
+// Min returns the minimum element of the set s, or the largest value of E
+// (MaxInt for int) if s is empty.
+func (s *SyncIntSet[E]) Min() E {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.s.Min()
+}
+
+// String returns a human-readable description of the set s.
+func (s *SyncIntSet[E]) String() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.s.String()
+}
+
+// Snapshot returns a copy of the current contents of the set s as an
+// IntSet, which the caller may use without locking.
+func (s *SyncIntSet[E]) Snapshot() *IntSet[E] {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.s.Copy()
+}
+
+// All returns an iterator over the elements of the set s in order,
+// as of the start of the iteration. The set may be mutated during
+// iteration.
+func (s *SyncIntSet[E]) All() iter.Seq[E] {
+	return func(yield func(E) bool) {
+		s.Snapshot().All()(yield)
+	}
+}
+
+// MarshalBinary implements the encoding.BinaryMarshaler interface.
+func (s *SyncIntSet[E]) MarshalBinary() ([]byte, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.s.MarshalBinary()
+}
+
+// UnmarshalBinary implements the encoding.BinaryUnmarshaler interface.
+func (s *SyncIntSet[E]) UnmarshalBinary(data []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.s.UnmarshalBinary(data)
+}
+
+// Add adds the non-negative value x to the set s, and reports whether
+// the set grew. Among concurrent calls adding the same absent value,
+// exactly one reports true.
+func (s *SyncIntSet[E]) Add(x E) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.s.Add(x)
+}
+
+// TryAdd is an alias for Add, for callers claiming a value: it adds
+// the non-negative value x to the set s and reports whether this call
+// added it. Among concurrent calls claiming the same absent value,
+// exactly one reports true.
+func (s *SyncIntSet[E]) TryAdd(x E) bool {
+	return s.Add(x)
+}
+
+// AddAll adds a group of non-negative value xs to the set.
+func (s *SyncIntSet[E]) AddAll(xs ...E) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.s.AddAll(xs...)
+}
+
+// Remove remove x from the set s, and reports whether the set shrank.
+func (s *SyncIntSet[E]) Remove(x E) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.s.Remove(x)
+}
+
+// Clear remove all elements from the set s.
+func (s *SyncIntSet[E]) Clear() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.s.Clear()
+}
+
+// UnionWith sets s to the union s ∪ t.
+//
+// t must not be mutated concurrently; pass a Snapshot to combine
+// two SyncIntSets.
+func (s *SyncIntSet[E]) UnionWith(t *IntSet[E]) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.s.UnionWith(t)
+}
+
+// IntersectWith sets s to the intersection s ∩ t.
+//
+// t must not be mutated concurrently; pass a Snapshot to combine
+// two SyncIntSets.
+func (s *SyncIntSet[E]) IntersectWith(t *IntSet[E]) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.s.IntersectWith(t)
+}
+
+// DifferenceWith sets s to the difference s ∖ t.
+//
+// t must not be mutated concurrently; pass a Snapshot to combine
+// two SyncIntSets.
+func (s *SyncIntSet[E]) DifferenceWith(t *IntSet[E]) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.s.DifferenceWith(t)
+}
+
+// SymmetricDifference sets s to the symmetric difference s ∆ t.
+//
+// t must not be mutated concurrently; pass a Snapshot to combine
+// two SyncIntSets.
+func (s *SyncIntSet[E]) SymmetricDifference(t *IntSet[E]) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.s.SymmetricDifference(t)
+}
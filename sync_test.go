@@ -0,0 +1,177 @@
+package intset_test
+
+import (
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/weiwenchen2022/intset"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// TestSyncIntSetConcurrent is meant to be run with -race.
+func TestSyncIntSetConcurrent(t *testing.T) {
+	t.Parallel()
+
+	const (
+		goroutines = 8
+		n          = 1000
+	)
+
+	var s intset.SyncIntSet[int]
+
+	var wg sync.WaitGroup
+	for g := 0; g < goroutines; g++ {
+		wg.Add(1)
+		go func(seed int64) {
+			defer wg.Done()
+
+			r := rand.New(rand.NewSource(seed))
+			var other intset.IntSet[int]
+			other.AddAll(1, 9, 144)
+
+			for i := 0; i < n; i++ {
+				x := r.Intn(1 << 12)
+				switch r.Intn(10) {
+				case 0:
+					s.Add(x)
+				case 1:
+					s.Remove(x)
+				case 2:
+					s.UnionWith(&other)
+				case 3:
+					s.Has(x)
+				case 4:
+					s.Len()
+				case 5:
+					s.Elems()
+				case 6:
+					s.Min()
+					s.Max()
+				case 7:
+					for y := range s.All() {
+						s.Remove(y) // mutating during iteration is allowed
+						break
+					}
+				case 8:
+					if _, err := s.MarshalBinary(); err != nil {
+						t.Error(err)
+					}
+				case 9:
+					_ = s.String()
+				}
+			}
+		}(int64(g))
+	}
+	wg.Wait()
+
+	if got, want := s.Len(), len(s.Elems()); got != want {
+		t.Errorf("Len: got %d, want %d", got, want)
+	}
+}
+
+func TestSyncIntSetAddOnce(t *testing.T) {
+	t.Parallel()
+
+	const goroutines = 32
+
+	var s intset.SyncIntSet[int]
+	var wg sync.WaitGroup
+	results := make(chan bool, goroutines)
+
+	for g := 0; g < goroutines; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results <- s.Add(42)
+		}()
+	}
+	wg.Wait()
+	close(results)
+
+	n := 0
+	for ok := range results {
+		if ok {
+			n++
+		}
+	}
+
+	if n != 1 {
+		t.Errorf("concurrent Add(42): %d calls reported true, want 1", n)
+	}
+}
+
+func TestSyncIntSetTryAdd(t *testing.T) {
+	t.Parallel()
+
+	const goroutines = 32
+
+	var s intset.SyncIntSet[int]
+	var wg sync.WaitGroup
+	var won atomic.Int32
+
+	for g := 0; g < goroutines; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if s.TryAdd(7) {
+				won.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if n := won.Load(); n != 1 {
+		t.Errorf("concurrent TryAdd(7): %d calls reported true, want 1", n)
+	}
+	if s.TryAdd(7) {
+		t.Error("TryAdd(7) on a set holding 7: got true")
+	}
+}
+
+func TestSyncIntSetOps(t *testing.T) {
+	t.Parallel()
+
+	var s intset.SyncIntSet[int]
+	s.AddAll(1, 144, 9)
+
+	var t1 intset.IntSet[int]
+	t1.AddAll(9, 42)
+
+	s.UnionWith(&t1)
+	if want, got := "{1 9 42 144}", s.String(); !cmp.Equal(want, got) {
+		t.Error(cmp.Diff(want, got))
+	}
+
+	s.IntersectWith(&t1)
+	if want, got := "{9 42}", s.String(); !cmp.Equal(want, got) {
+		t.Error(cmp.Diff(want, got))
+	}
+
+	snap := s.Snapshot()
+	s.DifferenceWith(&t1)
+	if !s.IsEmpty() || snap.Len() != 2 {
+		t.Errorf("DifferenceWith: got %s and snapshot %s", &s, snap)
+	}
+
+	s.SymmetricDifference(&t1)
+	data, err := s.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var u intset.SyncIntSet[int]
+	if err := u.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+	if want, got := "{9 42}", u.String(); !cmp.Equal(want, got) {
+		t.Error(cmp.Diff(want, got))
+	}
+
+	u.Clear()
+	if !u.IsEmpty() {
+		t.Errorf("Clear: got %s", &u)
+	}
+}
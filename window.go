@@ -0,0 +1,52 @@
+package intset
+
+// Window holds the most recent sets pushed into it, up to a fixed
+// size, and computes their union.
+//
+// The zero value is not usable; use NewWindow.
+type Window[E Integer] struct {
+	sets []*IntSet[E]
+	head int // index of the oldest set
+	n    int // number of sets held
+}
+
+// NewWindow returns an empty window holding at most size sets.
+// It panics if size is not positive.
+func NewWindow[E Integer](size int) *Window[E] {
+	if size <= 0 {
+		panic("intset: non-positive window size")
+	}
+
+	return &Window[E]{sets: make([]*IntSet[E], size)}
+}
+
+// Len returns the number of sets currently held by the window w.
+func (w *Window[E]) Len() int {
+	return w.n
+}
+
+// Push adds a copy of s to the window w, evicting the oldest set if
+// the window is full.
+func (w *Window[E]) Push(s *IntSet[E]) {
+	i := (w.head + w.n) % len(w.sets)
+	w.sets[i] = s.Copy()
+
+	if w.n < len(w.sets) {
+		w.n++
+	} else {
+		w.head = (w.head + 1) % len(w.sets)
+	}
+}
+
+// Union returns a new set holding the union of the sets in the window w.
+//
+// The union is recomputed on every call, so it takes time proportional
+// to the total number of words of the sets in the window.
+func (w *Window[E]) Union() *IntSet[E] {
+	u := &IntSet[E]{}
+	for i := 0; i < w.n; i++ {
+		u.UnionWith(w.sets[(w.head+i)%len(w.sets)])
+	}
+
+	return u
+}
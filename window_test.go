@@ -0,0 +1,48 @@
+package intset_test
+
+import (
+	"testing"
+
+	"github.com/weiwenchen2022/intset"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestWindow(t *testing.T) {
+	t.Parallel()
+
+	w := intset.NewWindow[int](2)
+	if got := w.Union().String(); got != "{}" {
+		t.Errorf("Union of empty window: got %q, want \"{}\"", got)
+	}
+
+	testcases := []struct {
+		push []int
+		want string
+		len  int
+	}{
+		{[]int{1, 2}, "{1 2}", 1},
+		{[]int{2, 300}, "{1 2 300}", 2},
+		{[]int{5}, "{2 5 300}", 2},      // evicts {1 2}
+		{nil, "{5}", 2},                 // evicts {2 300}
+		{[]int{1000}, "{1000}", 2},      // evicts {5}
+		{[]int{7, 1000}, "{7 1000}", 2}, // evicts {}
+	}
+
+	for i, tc := range testcases {
+		var s intset.IntSet[int]
+		s.AddAll(tc.push...)
+		w.Push(&s)
+
+		// Mutating the pushed set must not affect the window.
+		s.Add(42)
+
+		if got := w.Union().String(); !cmp.Equal(tc.want, got) {
+			t.Errorf("Union #%d: %s", i, cmp.Diff(tc.want, got))
+		}
+
+		if got := w.Len(); got != tc.len {
+			t.Errorf("Len #%d: got %d, want %d", i, got, tc.len)
+		}
+	}
+}